@@ -0,0 +1,113 @@
+package gen
+
+import (
+	"encoding/xml"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// FeedFilename is the name of the Atom feed of the releases.
+const FeedFilename = "changelog.xml"
+
+// FeedContentType is the content type of the Atom feed.
+const FeedContentType = "application/atom+xml"
+
+const dateLayout = "2006-01-02"
+
+// Feed is the Atom feed of the releases of a changelog.
+type Feed struct {
+	Title string
+	// Link is the absolute URL of the changelog page and SelfLink the
+	// absolute URL of the feed.
+	Link     string
+	SelfLink string
+	Releases []Release
+	// Content returns the HTML of the changes of a release.
+	Content func(Release) string
+}
+
+type atomFeed struct {
+	XMLName xml.Name    `xml:"http://www.w3.org/2005/Atom feed"`
+	Title   string      `xml:"title"`
+	ID      string      `xml:"id"`
+	Links   []atomLink  `xml:"link"`
+	Updated string      `xml:"updated"`
+	Entries []atomEntry `xml:"entry"`
+}
+
+type atomLink struct {
+	Rel  string `xml:"rel,attr,omitempty"`
+	Href string `xml:"href,attr"`
+}
+
+type atomEntry struct {
+	Title   string      `xml:"title"`
+	ID      string      `xml:"id"`
+	Link    atomLink    `xml:"link"`
+	Updated string      `xml:"updated"`
+	Content atomContent `xml:"content"`
+}
+
+type atomContent struct {
+	Type string `xml:"type,attr"`
+	Body string `xml:",chardata"`
+}
+
+// BuildFeed generates an Atom feed with an entry for each release with a
+// date, the unreleased changes are left out. The feed is updated at the
+// date of the latest release.
+func BuildFeed(feed Feed) (_ []byte, err error) {
+	atom := atomFeed{
+		Title: feed.Title,
+		ID:    feed.Link,
+		Links: []atomLink{{Href: feed.Link}, {Rel: "self", Href: feed.SelfLink}},
+	}
+
+	var updated time.Time
+
+	for _, release := range feed.Releases {
+		if release.Date == "" {
+			continue
+		}
+
+		date, err := time.Parse(dateLayout, release.Date)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid date of [%s]", release.Version)
+		}
+
+		if date.After(updated) {
+			updated = date
+		}
+
+		title := release.Version
+		if release.Yanked {
+			title += " [YANKED]"
+		}
+
+		link := feed.Link + "#" + release.ID
+
+		atom.Entries = append(atom.Entries, atomEntry{
+			Title:   title,
+			ID:      link,
+			Link:    atomLink{Href: link},
+			Updated: date.Format(time.RFC3339),
+			Content: atomContent{Type: "html", Body: feed.Content(release)},
+		})
+	}
+
+	if len(atom.Entries) == 0 {
+		err = errors.New("the changelog has no releases with a date")
+		return
+	}
+
+	atom.Updated = updated.Format(time.RFC3339)
+
+	bs, err := xml.MarshalIndent(atom, "", "  ")
+	if err != nil {
+		err = errors.Wrap(err, "xml.MarshalIndent failed")
+		return
+	}
+
+	return append([]byte(xml.Header), append(bs, '\n')...), nil
+}
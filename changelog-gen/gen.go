@@ -0,0 +1,133 @@
+package gen
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/lonnblad/go-service-doc/utils"
+)
+
+// Filename is the name of the changelog file that is recognized.
+const Filename = "CHANGELOG.md"
+
+// releaseRegexp matches the heading of a release in the Keep a Changelog
+// format, i.e. ## [1.2.0] - 2021-03-01, ## v1.2.0 or ## [Unreleased], with
+// an optional [YANKED] marker.
+var releaseRegexp = regexp.MustCompile(`^\[?((?i:unreleased)|v?\d[^\]\s]*)\]?(?:\s+-\s+(\d{4}-\d{2}-\d{2}))?(\s+\[YANKED\])?$`)
+
+var (
+	headingRegexp    = regexp.MustCompile(`^(#{1,2})\s+(.*?)\s*$`)
+	explicitIDRegexp = regexp.MustCompile(`\s*\{#([^}]+)\}$`)
+)
+
+// Release is a release of the changelog, the unreleased changes have the
+// version Unreleased and no date.
+type Release struct {
+	Version string
+	Date    string
+	Yanked  bool
+	// ID is the ID of the heading of the release, i.e. v1-2-0.
+	ID string
+}
+
+// IsChangelog returns true if the path is a CHANGELOG.md.
+func IsChangelog(path string) bool {
+	return strings.EqualFold(path[strings.LastIndexAny(path, `/\`)+1:], Filename)
+}
+
+// BuildMarkdown returns the Markdown of a changelog with IDs added to the
+// title and to the headings of the releases, so that the releases are
+// added to the menu. Headings that already have an ID keep it.
+func BuildMarkdown(content []byte) []byte {
+	lines := strings.Split(string(content), "\n")
+
+	forEachHeading(lines, func(idx, level int, title string) {
+		if explicitIDRegexp.MatchString(title) {
+			return
+		}
+
+		id := utils.Slug(title)
+
+		if level == 2 {
+			release, ok := parseRelease(title)
+			if !ok {
+				return
+			}
+
+			id = release.ID
+		}
+
+		lines[idx] = strings.Repeat("#", level) + " " + title + " {#" + id + "}"
+	})
+
+	return []byte(strings.Join(lines, "\n"))
+}
+
+// Releases returns the releases of a changelog, in the order of the file.
+func Releases(content []byte) (releases []Release) {
+	forEachHeading(strings.Split(string(content), "\n"), func(_, level int, title string) {
+		if level != 2 {
+			return
+		}
+
+		id := ""
+		if match := explicitIDRegexp.FindStringSubmatch(title); match != nil {
+			title, id = strings.TrimSuffix(title, match[0]), match[1]
+		}
+
+		if release, ok := parseRelease(title); ok {
+			if id != "" {
+				release.ID = id
+			}
+
+			releases = append(releases, release)
+		}
+	})
+
+	return
+}
+
+// forEachHeading calls fn for each heading of level 1 and 2, headings in
+// code blocks are skipped.
+func forEachHeading(lines []string, fn func(idx, level int, title string)) {
+	var fence string
+
+	for idx, line := range lines {
+		trimmed := strings.TrimSpace(line)
+
+		if fence != "" {
+			if strings.HasPrefix(trimmed, fence) {
+				fence = ""
+			}
+
+			continue
+		}
+
+		if strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") {
+			fence = trimmed[:3]
+			continue
+		}
+
+		if match := headingRegexp.FindStringSubmatch(line); match != nil {
+			fn(idx, len(match[1]), match[2])
+		}
+	}
+}
+
+func parseRelease(title string) (release Release, ok bool) {
+	match := releaseRegexp.FindStringSubmatch(title)
+	if match == nil {
+		return
+	}
+
+	release = Release{Version: match[1], Date: match[2], Yanked: match[3] != ""}
+
+	// IDs of versions are prefixed with v, since an ID can't start with
+	// a digit in HTML 4 and CSS selectors.
+	release.ID = utils.Slug(release.Version)
+	if release.ID != "" && release.ID[0] >= '0' && release.ID[0] <= '9' {
+		release.ID = "v" + release.ID
+	}
+
+	return release, release.ID != ""
+}
@@ -0,0 +1,129 @@
+package gen_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	changelog_gen "github.com/lonnblad/go-service-doc/changelog-gen"
+)
+
+const changelog = `# Changelog
+
+## [Unreleased]
+
+### Added
+
+- Something new.
+
+## Notes
+
+` + "```" + `
+## 0.9.0 - 2020-01-01
+` + "```" + `
+
+## [1.1.0] - 2021-03-01 [YANKED]
+
+## v1.0.0 - 2021-01-15 {#first-release}
+`
+
+func Test_BuildMarkdown(t *testing.T) {
+	expected := `# Changelog {#changelog}
+
+## [Unreleased] {#unreleased}
+
+### Added
+
+- Something new.
+
+## Notes
+
+` + "```" + `
+## 0.9.0 - 2020-01-01
+` + "```" + `
+
+## [1.1.0] - 2021-03-01 [YANKED] {#v1-1-0}
+
+## v1.0.0 - 2021-01-15 {#first-release}
+`
+
+	assert.Equal(t, expected, string(changelog_gen.BuildMarkdown([]byte(changelog))))
+}
+
+func Test_Releases(t *testing.T) {
+	expected := []changelog_gen.Release{
+		{Version: "Unreleased", ID: "unreleased"},
+		{Version: "1.1.0", Date: "2021-03-01", Yanked: true, ID: "v1-1-0"},
+		{Version: "v1.0.0", Date: "2021-01-15", ID: "first-release"},
+	}
+
+	assert.Equal(t, expected, changelog_gen.Releases([]byte(changelog)))
+}
+
+func Test_IsChangelog(t *testing.T) {
+	assert.True(t, changelog_gen.IsChangelog("docs/CHANGELOG.md"))
+	assert.True(t, changelog_gen.IsChangelog("changelog.md"))
+	assert.False(t, changelog_gen.IsChangelog("docs/CHANGELOG.txt"))
+	assert.False(t, changelog_gen.IsChangelog("docs/bars.md"))
+}
+
+func Test_BuildFeed(t *testing.T) {
+	feed, err := changelog_gen.BuildFeed(changelog_gen.Feed{
+		Title:    "Bars Changelog",
+		Link:     "https://bars.example.com/docs/changelog",
+		SelfLink: "https://bars.example.com/docs/changelog.xml",
+		Releases: changelog_gen.Releases([]byte(changelog)),
+		Content: func(release changelog_gen.Release) string {
+			return "<p>" + release.Version + " & more</p>"
+		},
+	})
+	require.NoError(t, err)
+
+	expected := `<?xml version="1.0" encoding="UTF-8"?>
+<feed xmlns="http://www.w3.org/2005/Atom">
+  <title>Bars Changelog</title>
+  <id>https://bars.example.com/docs/changelog</id>
+  <link href="https://bars.example.com/docs/changelog"></link>
+  <link rel="self" href="https://bars.example.com/docs/changelog.xml"></link>
+  <updated>2021-03-01T00:00:00Z</updated>
+  <entry>
+    <title>1.1.0 [YANKED]</title>
+    <id>https://bars.example.com/docs/changelog#v1-1-0</id>
+    <link href="https://bars.example.com/docs/changelog#v1-1-0"></link>
+    <updated>2021-03-01T00:00:00Z</updated>
+    <content type="html">&lt;p&gt;1.1.0 &amp; more&lt;/p&gt;</content>
+  </entry>
+  <entry>
+    <title>v1.0.0</title>
+    <id>https://bars.example.com/docs/changelog#first-release</id>
+    <link href="https://bars.example.com/docs/changelog#first-release"></link>
+    <updated>2021-01-15T00:00:00Z</updated>
+    <content type="html">&lt;p&gt;v1.0.0 &amp; more&lt;/p&gt;</content>
+  </entry>
+</feed>
+`
+
+	assert.Equal(t, expected, string(feed))
+}
+
+func Test_BuildFeed_Errors(t *testing.T) {
+	testcases := []struct {
+		name     string
+		releases []changelog_gen.Release
+	}{
+		{name: "no releases", releases: []changelog_gen.Release{{Version: "Unreleased", ID: "unreleased"}}},
+		{name: "invalid date", releases: []changelog_gen.Release{{Version: "1.0.0", Date: "2021-13-01", ID: "v1-0-0"}}},
+	}
+
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := changelog_gen.BuildFeed(changelog_gen.Feed{
+				Releases: tc.releases,
+				Content:  func(changelog_gen.Release) string { return "" },
+			})
+			assert.Error(t, err)
+		})
+	}
+}
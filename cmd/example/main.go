@@ -10,11 +10,18 @@ import (
 const port = "8080"
 
 func main() {
-	server := &http.Server{Addr: ":" + port, Handler: service_docs.Handler()}
+	// docs:start handler
+	handler, err := service_docs.Handler()
+	if err != nil {
+		log.Fatalf("Failed to create the docs handler: %v", err)
+	}
+
+	server := &http.Server{Addr: ":" + port, Handler: handler}
+	// docs:end
 
 	log.Printf("Will start to listen and serve on port %s", port)
 
-	if err := server.ListenAndServe(); err != http.ErrServerClosed {
+	if err = server.ListenAndServe(); err != http.ErrServerClosed {
 		log.Fatal("HTTP server ListenAndServe")
 	}
 }
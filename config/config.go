@@ -0,0 +1,86 @@
+package config
+
+import (
+	"io/ioutil"
+
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v2"
+)
+
+// Config is the configuration read from the config file, options that
+// are missing in the file keep their default values.
+type Config struct {
+	Markdown Markdown `yaml:"markdown"`
+	// GoPackages are the directories of the Go packages to generate
+	// reference pages for from their doc comments.
+	GoPackages []string `yaml:"go_packages"`
+	// BaseURL is the URL the documentation is published at, without the
+	// base path, i.e. https://bars.example.com. It's used for the absolute
+	// links of the changelog feed.
+	BaseURL   string    `yaml:"base_url"`
+	Changelog Changelog `yaml:"changelog"`
+	// GitHistory reads when each page was last changed and by whom from
+	// the git history of its source, which requires git to be installed.
+	GitHistory bool `yaml:"git_history"`
+}
+
+// Changelog configures the page generated from a CHANGELOG.md in the
+// Keep a Changelog format, a CHANGELOG.md in the source directory is
+// always recognized.
+type Changelog struct {
+	// Path is the path of a CHANGELOG.md outside of the source directory,
+	// i.e. in the root of the repository.
+	Path string `yaml:"path"`
+	// Feed enables an Atom feed of the releases, which requires BaseURL.
+	Feed bool `yaml:"feed"`
+}
+
+// Markdown contains the Markdown extensions, all of them are enabled by
+// default.
+type Markdown struct {
+	Footnotes       bool `yaml:"footnotes"`
+	DefinitionLists bool `yaml:"definition_lists"`
+	Strikethrough   bool `yaml:"strikethrough"`
+	Autolink        bool `yaml:"autolink"`
+	TaskLists       bool `yaml:"task_lists"`
+	// HardLineBreaks renders newlines in paragraphs as line breaks, like
+	// the Markdown renderer used before goldmark.
+	HardLineBreaks bool `yaml:"hard_line_breaks"`
+}
+
+// Default returns the default configuration.
+func Default() Config {
+	return Config{
+		Markdown: Markdown{
+			Footnotes:       true,
+			DefinitionLists: true,
+			Strikethrough:   true,
+			Autolink:        true,
+			TaskLists:       true,
+			HardLineBreaks:  true,
+		},
+	}
+}
+
+// Load reads the YAML config file at path, the default configuration is
+// returned if path is empty.
+func Load(path string) (_ Config, err error) {
+	cfg := Default()
+
+	if path == "" {
+		return cfg, nil
+	}
+
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		err = errors.Wrap(err, "ioutil.ReadFile failed")
+		return
+	}
+
+	if err = yaml.UnmarshalStrict(content, &cfg); err != nil {
+		err = errors.Wrapf(err, "yaml.UnmarshalStrict failed for [%s]", path)
+		return
+	}
+
+	return cfg, nil
+}
@@ -0,0 +1,91 @@
+package config_test
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lonnblad/go-service-doc/config"
+)
+
+func Test_Load(t *testing.T) {
+	testcases := []struct {
+		name     string
+		content  string
+		expected func(cfg *config.Config)
+		err      bool
+	}{
+		{name: "empty file", content: "", expected: func(cfg *config.Config) {}},
+		{
+			name:    "disabled extensions",
+			content: "markdown:\n  footnotes: false\n  task_lists: false\n  hard_line_breaks: false\n",
+			expected: func(cfg *config.Config) {
+				cfg.Markdown.Footnotes = false
+				cfg.Markdown.TaskLists = false
+				cfg.Markdown.HardLineBreaks = false
+			},
+		},
+		{
+			name:    "go packages",
+			content: "go_packages:\n  - ./client\n",
+			expected: func(cfg *config.Config) {
+				cfg.GoPackages = []string{"./client"}
+			},
+		},
+		{
+			name:    "changelog",
+			content: "base_url: https://bars.example.com\nchangelog:\n  path: ../CHANGELOG.md\n  feed: true\n",
+			expected: func(cfg *config.Config) {
+				cfg.BaseURL = "https://bars.example.com"
+				cfg.Changelog = config.Changelog{Path: "../CHANGELOG.md", Feed: true}
+			},
+		},
+		{
+			name:    "git history",
+			content: "git_history: true\n",
+			expected: func(cfg *config.Config) {
+				cfg.GitHistory = true
+			},
+		},
+		{name: "unknown option", content: "markdown:\n  emoji: true\n", err: true},
+		{name: "invalid yaml", content: "markdown: [", err: true},
+	}
+
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			file, err := ioutil.TempFile("", "go-service-doc-*.yaml")
+			require.NoError(t, err)
+
+			defer os.Remove(file.Name())
+
+			_, err = file.WriteString(tc.content)
+			require.NoError(t, err)
+			require.NoError(t, file.Close())
+
+			cfg, err := config.Load(file.Name())
+			if tc.err {
+				require.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+
+			expected := config.Default()
+			tc.expected(&expected)
+			assert.Equal(t, expected, cfg)
+		})
+	}
+}
+
+func Test_Load_WithoutFile(t *testing.T) {
+	cfg, err := config.Load("")
+	require.NoError(t, err)
+	assert.Equal(t, config.Default(), cfg)
+
+	_, err = config.Load("missing.yaml")
+	require.Error(t, err)
+}
@@ -2,18 +2,37 @@ package core
 
 import (
 	"sort"
+	"time"
+
+	"github.com/lonnblad/go-service-doc/utils"
 )
 
 type Pages []Page
 
 type Page struct {
-	Name           string
-	WebPath        string
-	Filepath       string
-	Markdown       string
-	HTML           string
+	Name     string
+	WebPath  string
+	Filepath string
+	Markdown string
+	HTML     string
+	// StaticHTML is the HTML of the page without the suggestions of the
+	// search, which need the suggest endpoint of the Go handler.
+	StaticHTML  string
+	Tags        []string
+	Title       string
+	Description string
+	// Image is the href of the preview image of the page.
+	Image          string
 	Headers        []Header
 	IndexDocuments []IndexDocument
+	// LastModified is when the source of the page was last modified, or
+	// when it was last committed if the git history is read.
+	LastModified time.Time
+	// LastModifiedBy is the author of the last commit of the source and
+	// Contributors are the authors of all the commits, the latest first.
+	// Both are only set if the git history is read.
+	LastModifiedBy string
+	Contributors   []string
 }
 
 type Header struct {
@@ -27,6 +46,7 @@ type IndexDocument struct {
 	Link    string
 	Context []string
 	Content []string
+	Code    []string
 	HTML    string
 }
 
@@ -62,6 +82,19 @@ type File struct {
 	Name        string
 	Href        string
 	Path        string
+	Fingerprint string
 	ContentType string
 	Content     []byte
 }
+
+// FingerprintedHref returns the href with the fingerprint of the content,
+// i.e. /docs/static/bars.3f2a9c.svg.
+func (f File) FingerprintedHref() string {
+	return utils.AddFingerprint(f.Href, f.Fingerprint)
+}
+
+// FingerprintedPath returns the path with the fingerprint of the content,
+// i.e. docs/static/bars.3f2a9c.svg.
+func (f File) FingerprintedPath() string {
+	return utils.AddFingerprint(f.Path, f.Fingerprint)
+}
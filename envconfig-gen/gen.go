@@ -0,0 +1,219 @@
+package gen
+
+import (
+	"fmt"
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"os"
+	"reflect"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/lonnblad/go-service-doc/utils"
+)
+
+// maxDepth limits how deep nested structs are followed.
+const maxDepth = 8
+
+// BuildMarkdown generates a Markdown table with the environment variables
+// of the struct named typeName in the Go package in dir. The variables are
+// read from the env tags of the fields, i.e. `env:"DB_HOST"`, and the
+// defaults from the default or envDefault tags. Fields without an env tag
+// that are structs are included with the envPrefix tag as prefix.
+func BuildMarkdown(dir, typeName string) (_ []byte, err error) {
+	fset := token.NewFileSet()
+
+	notTest := func(info os.FileInfo) bool {
+		return !strings.HasSuffix(info.Name(), "_test.go")
+	}
+
+	pkgs, err := parser.ParseDir(fset, dir, notTest, parser.ParseComments)
+	if err != nil {
+		err = errors.Wrap(err, "parser.ParseDir failed")
+		return
+	}
+
+	var files []*ast.File
+
+	for _, pkg := range pkgs {
+		for _, file := range pkg.Files {
+			files = append(files, file)
+		}
+	}
+
+	if len(files) == 0 {
+		err = errors.Errorf("no Go package found in [%s]", dir)
+		return
+	}
+
+	// Type errors, i.e. for dependencies that can't be imported, are
+	// ignored, the types that can't be resolved are written as in the code.
+	conf := types.Config{
+		Importer: importer.ForCompiler(fset, "source", nil),
+		Error:    func(error) {},
+	}
+
+	pkg, _ := conf.Check(dir, fset, files, nil)
+
+	obj, ok := pkg.Scope().Lookup(typeName).(*types.TypeName)
+	if !ok {
+		err = errors.Errorf("type [%s] not found in [%s]", typeName, dir)
+		return
+	}
+
+	st, ok := obj.Type().Underlying().(*types.Struct)
+	if !ok {
+		err = errors.Errorf("[%s] in [%s] isn't a struct", typeName, dir)
+		return
+	}
+
+	gen := &generator{pkg: pkg, fields: map[token.Pos]*ast.Field{}}
+
+	for _, file := range files {
+		ast.Inspect(file, func(node ast.Node) bool {
+			if field, ok := node.(*ast.Field); ok {
+				gen.addField(field)
+			}
+
+			return true
+		})
+	}
+
+	rows := gen.rows(st, "", 0)
+	if len(rows) == 0 {
+		err = errors.Errorf("[%s] in [%s] has no fields with env tags", typeName, dir)
+		return
+	}
+
+	var md strings.Builder
+
+	md.WriteString("| Variable | Type | Default | Required | Description |\n")
+	md.WriteString("| -------- | ---- | ------- | -------- | ----------- |\n")
+
+	for _, row := range rows {
+		md.WriteString(row + "\n")
+	}
+
+	return []byte(md.String()), nil
+}
+
+type generator struct {
+	pkg *types.Package
+	// fields are the fields of the structs in the package by the position
+	// of their names, used to find the doc comments of the fields.
+	fields map[token.Pos]*ast.Field
+}
+
+func (gen *generator) addField(field *ast.Field) {
+	if len(field.Names) == 0 {
+		gen.fields[field.Type.Pos()] = field
+		return
+	}
+
+	for _, name := range field.Names {
+		gen.fields[name.Pos()] = field
+	}
+}
+
+func (gen *generator) rows(st *types.Struct, prefix string, depth int) (rows []string) {
+	if depth > maxDepth {
+		return
+	}
+
+	for idx := 0; idx < st.NumFields(); idx++ {
+		field := st.Field(idx)
+		tag := reflect.StructTag(st.Tag(idx))
+
+		env := tag.Get("env")
+		if !field.Exported() || env == "-" {
+			continue
+		}
+
+		if env == "" {
+			if nested, ok := underlyingStruct(field.Type()); ok {
+				rows = append(rows, gen.rows(nested, prefix+tag.Get("envPrefix"), depth+1)...)
+			}
+
+			continue
+		}
+
+		options := strings.Split(env, ",")
+		required := tag.Get("required") == "true"
+
+		for _, option := range options[1:] {
+			required = required || option == "required" || option == "notEmpty"
+		}
+
+		defaultValue := tag.Get("default")
+		if defaultValue == "" {
+			defaultValue = tag.Get("envDefault")
+		}
+
+		if defaultValue != "" {
+			defaultValue = "`" + defaultValue + "`"
+		}
+
+		rows = append(rows, fmt.Sprintf("| `%s` | `%s` | %s | %s | %s |",
+			prefix+options[0], gen.typeName(field), defaultValue, yesNo(required), utils.EscapeTableCell(gen.doc(field))))
+	}
+
+	return rows
+}
+
+func underlyingStruct(typ types.Type) (*types.Struct, bool) {
+	if ptr, ok := typ.(*types.Pointer); ok {
+		typ = ptr.Elem()
+	}
+
+	st, ok := typ.Underlying().(*types.Struct)
+
+	return st, ok
+}
+
+// typeName returns the type of the field, qualified with the package name
+// for types in other packages, i.e. time.Duration.
+func (gen *generator) typeName(field *types.Var) string {
+	if astField, exists := gen.fields[field.Pos()]; exists && !isValid(field.Type()) {
+		return types.ExprString(astField.Type)
+	}
+
+	return types.TypeString(field.Type(), func(pkg *types.Package) string {
+		if pkg == gen.pkg {
+			return ""
+		}
+
+		return pkg.Name()
+	})
+}
+
+func isValid(typ types.Type) bool {
+	basic, ok := typ.(*types.Basic)
+	return !ok || basic.Kind() != types.Invalid
+}
+
+// doc returns the doc comment of the field, or the comment on the line of
+// the field if it doesn't have one.
+func (gen *generator) doc(field *types.Var) string {
+	astField, exists := gen.fields[field.Pos()]
+	if !exists {
+		return ""
+	}
+
+	if text := astField.Doc.Text(); text != "" {
+		return text
+	}
+
+	return astField.Comment.Text()
+}
+
+func yesNo(b bool) string {
+	if b {
+		return "yes"
+	}
+
+	return "no"
+}
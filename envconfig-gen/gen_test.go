@@ -0,0 +1,47 @@
+package gen_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	envconfig_gen "github.com/lonnblad/go-service-doc/envconfig-gen"
+)
+
+func Test_BuildMarkdown(t *testing.T) {
+	md, err := envconfig_gen.BuildMarkdown("testdata/config", "Config")
+	require.NoError(t, err)
+
+	expected := "| Variable | Type | Default | Required | Description |\n" +
+		"| -------- | ---- | ------- | -------- | ----------- |\n" +
+		"| `PORT` | `int` | `8080` | no | Port is the port to listen on. |\n" +
+		"| `DB_HOST` | `string` |  | yes | Host is the host of the database. |\n" +
+		"| `DB_USER` | `string` |  | yes |  |\n" +
+		"| `DB_PASSWORD` | `string` |  | no |  |\n" +
+		"| `TIMEOUT` | `time.Duration` | `5s` | no | Timeout is the timeout of the requests. |\n" +
+		"| `LOG_LEVEL` | `missing.Level` |  | no | The level of the logs. |\n"
+
+	assert.Equal(t, expected, string(md))
+}
+
+func Test_BuildMarkdown_Errors(t *testing.T) {
+	testcases := []struct {
+		name     string
+		dir      string
+		typeName string
+	}{
+		{name: "missing package", dir: "testdata/missing", typeName: "Config"},
+		{name: "missing type", dir: "testdata/config", typeName: "Settings"},
+		{name: "not a struct", dir: "testdata/config", typeName: "Level"},
+		{name: "no env tags", dir: "testdata/config", typeName: "NoEnv"},
+	}
+
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := envconfig_gen.BuildMarkdown(tc.dir, tc.typeName)
+			assert.Error(t, err)
+		})
+	}
+}
@@ -0,0 +1,34 @@
+package config
+
+import (
+	"time"
+
+	"github.com/lonnblad/go-service-doc/envconfig-gen/testdata/missing"
+)
+
+// Config is the configuration of the service.
+type Config struct {
+	// Port is the port to listen on.
+	Port int `env:"PORT" default:"8080"`
+	// DB is the database to connect to.
+	DB Database `envPrefix:"DB_"`
+	// Timeout is the timeout of the requests.
+	Timeout time.Duration `env:"TIMEOUT" envDefault:"5s"`
+	Level   missing.Level `env:"LOG_LEVEL"` // The level of the logs.
+	Secret  string        `env:"-"`
+	debug   bool
+}
+
+// Database is the configuration of a database.
+type Database struct {
+	// Host is the host of the database.
+	Host     string `env:"HOST,required"`
+	User     string `env:"USER" required:"true"`
+	Password string `env:"PASSWORD"`
+}
+
+type NoEnv struct {
+	Port int
+}
+
+type Level string
@@ -10,20 +10,23 @@ import (
 	"github.com/lonnblad/go-service-doc/core"
 	go_gen "github.com/lonnblad/go-service-doc/go-pkg-gen"
 	html_gen "github.com/lonnblad/go-service-doc/html-gen"
+	search_gen "github.com/lonnblad/go-service-doc/search-gen"
 	"github.com/lonnblad/go-service-doc/utils"
 )
 
 type GoExporter struct {
 	pages       core.Pages
 	staticFiles core.Files
+	generated   core.Files
 	basepath    string
+	language    string
 	searchPage  string
 	outputDir   string
 	err         error
 }
 
 func NewExporter() *GoExporter {
-	return &GoExporter{}
+	return &GoExporter{language: search_gen.DefaultLanguage}
 }
 
 func (goex *GoExporter) WithPages(pages core.Pages) *GoExporter {
@@ -36,11 +39,23 @@ func (goex *GoExporter) WithStaticFiles(staticFiles core.Files) *GoExporter {
 	return goex
 }
 
+// WithGeneratedFiles sets the files generated from the pages, i.e. the
+// changelog feed, which are served at the base path.
+func (goex *GoExporter) WithGeneratedFiles(files core.Files) *GoExporter {
+	goex.generated = files
+	return goex
+}
+
 func (goex *GoExporter) WithBasepath(basepath string) *GoExporter {
 	goex.basepath = basepath
 	return goex
 }
 
+func (goex *GoExporter) WithLanguage(language string) *GoExporter {
+	goex.language = language
+	return goex
+}
+
 func (goex *GoExporter) WithSearchPage(searchPage string) *GoExporter {
 	goex.searchPage = searchPage
 	return goex
@@ -60,10 +75,24 @@ func (goex *GoExporter) Run() {
 
 	css := html_gen.GetMarkdownCSS()
 
+	zap.L().Info("building search index")
+
+	searchIndex, err := search_gen.New().
+		WithPages(goex.pages).
+		WithLanguage(goex.language).
+		Build()
+	if err != nil {
+		goex.err = errors.Wrap(err, "search_gen.Build failed")
+		return
+	}
+
 	fileContent, err := go_gen.New().
 		WithPages(goex.pages).
 		WithStaticFiles(goex.staticFiles).
+		WithGeneratedFiles(goex.generated).
+		WithSearchIndex(searchIndex).
 		WithCSS(string(css)).
+		WithCSSFilename(html_gen.GetMarkdownCSSFilename()).
 		WithBasePath(goex.basepath).
 		WithSearchPage(goex.searchPage).
 		Build()
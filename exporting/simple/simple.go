@@ -3,6 +3,7 @@ package simple
 import (
 	"io/ioutil"
 	"os"
+	"path"
 	"strings"
 
 	"github.com/pkg/errors"
@@ -18,6 +19,7 @@ type SimpleExporter struct {
 	outputDir   string
 	pages       core.Pages
 	staticFiles core.Files
+	generated   core.Files
 	err         error
 }
 
@@ -45,6 +47,13 @@ func (se *SimpleExporter) WithStaticFiles(staticFiles core.Files) *SimpleExporte
 	return se
 }
 
+// WithGeneratedFiles sets the files generated from the pages, i.e. the
+// changelog feed, which are written to the output directory.
+func (se *SimpleExporter) WithGeneratedFiles(files core.Files) *SimpleExporter {
+	se.generated = files
+	return se
+}
+
 func (se *SimpleExporter) Error() error {
 	return se.err
 }
@@ -57,30 +66,44 @@ func (se *SimpleExporter) Run() {
 		return
 	}
 
-	if err := exportHTMLPages(se.pages, se.sourceDir, se.outputDir); se.err != nil {
+	if err := exportHTMLPages(se.pages, se.outputDir); err != nil {
 		se.err = errors.Wrap(err, "exportHTMLPages failed")
 		return
 	}
 
-	if err := exportCSSFile(se.outputDir); se.err != nil {
+	if err := exportCSSFile(se.outputDir); err != nil {
 		se.err = errors.Wrap(err, "exportCSSFile failed")
 		return
 	}
 
-	if err := exportStaticFiles(se.staticFiles, se.sourceDir, se.outputDir); se.err != nil {
+	if err := exportStaticFiles(se.staticFiles, se.sourceDir, se.outputDir); err != nil {
 		se.err = errors.Wrap(err, "exportStaticFiles failed")
 		return
 	}
+
+	if err := exportGeneratedFiles(se.generated, se.outputDir); err != nil {
+		se.err = errors.Wrap(err, "exportGeneratedFiles failed")
+		return
+	}
 }
 
-func exportHTMLPages(pages core.Pages, sourceDir, outputDir string) error {
+func exportHTMLPages(pages core.Pages, outputDir string) error {
 	for _, page := range pages {
 		zap.L().With(zap.String("page", page.Name)).Info("exporting HTML file")
 
-		filepath := strings.ReplaceAll(page.Filepath, ".md", ".html")
-		filepath = strings.ReplaceAll(filepath, sourceDir, outputDir)
+		// Pages are generated from files in the source directory and from
+		// Go packages, which can be anywhere. Files named in upper case,
+		// i.e. CHANGELOG.md, are served in lower case.
+		filename := path.Base(page.Filepath)
+		filename = strings.TrimSuffix(filename, path.Ext(filename))
 
-		if err := ioutil.WriteFile(filepath, []byte(page.HTML), utils.FilePermission); err != nil {
+		if filename == strings.ToUpper(filename) {
+			filename = strings.ToLower(filename)
+		}
+
+		filepath := outputDir + "/" + filename + ".html"
+
+		if err := ioutil.WriteFile(filepath, []byte(page.StaticHTML), utils.FilePermission); err != nil {
 			return errors.Wrap(err, "ioutil.WriteFile failed")
 		}
 	}
@@ -90,12 +113,13 @@ func exportHTMLPages(pages core.Pages, sourceDir, outputDir string) error {
 
 func exportCSSFile(outputDir string) error {
 	css := html_gen.GetMarkdownCSS()
-	filepath := outputDir + "/markdown.css"
 
 	zap.L().With(zap.String("file", "markdown.css")).Info("exporting CSS file")
 
-	if err := ioutil.WriteFile(filepath, css, utils.FilePermission); err != nil {
-		return errors.Wrap(err, "ioutil.WriteFile failed")
+	for _, filename := range []string{"markdown.css", html_gen.GetMarkdownCSSFilename()} {
+		if err := ioutil.WriteFile(outputDir+"/"+filename, css, utils.FilePermission); err != nil {
+			return errors.Wrap(err, "ioutil.WriteFile failed")
+		}
 	}
 
 	return nil
@@ -107,7 +131,27 @@ func exportStaticFiles(staticFiles core.Files, sourceDir, outputDir string) erro
 
 		zap.L().With(zap.String("file", file.Name)).Info("exporting static file")
 
-		if err := ioutil.WriteFile(filepath, file.Content, utils.FilePermission); err != nil {
+		if err := os.MkdirAll(path.Dir(filepath), os.ModePerm); err != nil {
+			return errors.Wrap(err, "os.MkdirAll failed")
+		}
+
+		fingerprintedFilepath := strings.ReplaceAll(file.FingerprintedPath(), sourceDir, outputDir)
+
+		for _, exportPath := range []string{filepath, fingerprintedFilepath} {
+			if err := ioutil.WriteFile(exportPath, file.Content, utils.FilePermission); err != nil {
+				return errors.Wrap(err, "ioutil.WriteFile failed")
+			}
+		}
+	}
+
+	return nil
+}
+
+func exportGeneratedFiles(files core.Files, outputDir string) error {
+	for _, file := range files {
+		zap.L().With(zap.String("file", file.Path)).Info("exporting generated file")
+
+		if err := ioutil.WriteFile(outputDir+"/"+file.Path, file.Content, utils.FilePermission); err != nil {
 			return errors.Wrap(err, "ioutil.WriteFile failed")
 		}
 	}
@@ -9,15 +9,19 @@ import (
 	"github.com/pkg/errors"
 
 	"github.com/lonnblad/go-service-doc/core"
+	search_gen "github.com/lonnblad/go-service-doc/search-gen"
 )
 
 type Gen struct {
-	pages          core.Pages
-	staticFiles    core.Files
-	indexDocuments []core.IndexDocument
-	searchPage     string
-	css            string
-	basePath       string
+	pages       core.Pages
+	suggestions []suggestion
+	staticFiles core.Files
+	generated   core.Files
+	searchIndex search_gen.Index
+	searchPage  string
+	css         string
+	cssFilename string
+	basePath    string
 }
 
 func New() *Gen {
@@ -28,9 +32,10 @@ func (g *Gen) WithPages(pages core.Pages) *Gen {
 	for _, page := range pages {
 		page.HTML = strings.ReplaceAll(page.HTML, "`", "` + \"`\" + `")
 		g.pages = append(g.pages, page)
-		g.indexDocuments = append(g.indexDocuments, page.IndexDocuments...)
 	}
 
+	g.suggestions = buildSuggestions(pages)
+
 	return g
 }
 
@@ -39,6 +44,16 @@ func (g *Gen) WithStaticFiles(files core.Files) *Gen {
 	return g
 }
 
+func (g *Gen) WithGeneratedFiles(files core.Files) *Gen {
+	g.generated = files
+	return g
+}
+
+func (g *Gen) WithSearchIndex(searchIndex search_gen.Index) *Gen {
+	g.searchIndex = searchIndex
+	return g
+}
+
 func (g *Gen) WithSearchPage(page string) *Gen {
 	g.searchPage = page
 	return g
@@ -49,6 +64,11 @@ func (g *Gen) WithCSS(css string) *Gen {
 	return g
 }
 
+func (g *Gen) WithCSSFilename(cssFilename string) *Gen {
+	g.cssFilename = cssFilename
+	return g
+}
+
 func (g *Gen) WithBasePath(basePath string) *Gen {
 	g.basePath = basePath
 	return g
@@ -58,17 +78,23 @@ func (g *Gen) Build() (_ []byte, err error) {
 	templateInfo := struct {
 		Timestamp      time.Time
 		Pages          core.Pages
+		Suggestions    []suggestion
 		StaticFiles    core.Files
-		IndexDocuments []core.IndexDocument
+		GeneratedFiles core.Files
+		SearchIndex    search_gen.Index
 		CSS            string
+		CSSFilename    string
 		BasePath       string
 		SearchPage     string
 	}{
 		Timestamp:      time.Now(),
 		Pages:          g.pages,
+		Suggestions:    g.suggestions,
 		StaticFiles:    g.staticFiles,
-		IndexDocuments: g.indexDocuments,
+		GeneratedFiles: g.generated,
+		SearchIndex:    g.searchIndex,
 		CSS:            g.css,
+		CSSFilename:    g.cssFilename,
 		BasePath:       g.basePath,
 		SearchPage:     g.searchPage,
 	}
@@ -88,27 +114,89 @@ func (g *Gen) Build() (_ []byte, err error) {
 	return buffer.Bytes(), nil
 }
 
+type suggestion struct {
+	Title   string
+	Link    string
+	Context string
+}
+
+// buildSuggestions collects the headings of the menu followed by the
+// headings of the search index documents, unique by link.
+func buildSuggestions(pages core.Pages) (suggestions []suggestion) {
+	uniqueLinks := map[string]bool{}
+
+	add := func(s suggestion) {
+		if s.Title == "" || uniqueLinks[s.Link] {
+			return
+		}
+
+		uniqueLinks[s.Link] = true
+		suggestions = append(suggestions, s)
+	}
+
+	for _, page := range pages {
+		for _, header := range page.Headers {
+			add(suggestion{Title: header.Title, Link: header.Link})
+
+			for _, subHeader := range header.Headers {
+				add(suggestion{Title: subHeader.Title, Link: subHeader.Link, Context: header.Title})
+			}
+		}
+	}
+
+	for _, page := range pages {
+		for _, doc := range page.IndexDocuments {
+			if len(doc.Context) < 2 {
+				continue
+			}
+
+			// The first element of the context is the title of the service.
+			context := doc.Context[1 : len(doc.Context)-1]
+			add(suggestion{Title: doc.Context[len(doc.Context)-1], Link: doc.Link, Context: strings.Join(context, " > ")})
+		}
+	}
+
+	return
+}
+
 const packageTemplate = `// This file was generated by lonnblad/go-service-doc at
 // {{ .Timestamp }}
 package docs
 
 import (
+	"encoding/json"
+	"html"
 	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
 	"strings"
 
 	"github.com/blevesearch/bleve"
+	"github.com/blevesearch/bleve/search"
+	"github.com/blevesearch/bleve/search/query"
+
+	search_gen "github.com/lonnblad/go-service-doc/search-gen"
 )
 
 const contentType = "Content-Type"
 const mimeHTML = "text/html"
 const mimeCSS = "text/css"
+const mimeJSON = "application/json"
 
-func Handler() http.Handler {
-	index, _ := createSearchIndex()
+// Handler returns a http.Handler serving the documentation, it will
+// return an error if the embedded search index can't be opened.
+func Handler() (_ http.Handler, err error) {
+	index, err := search_gen.Open(searchIndex)
+	if err != nil {
+		return
+	}
 
 	mux := http.NewServeMux()
 	mux.HandleFunc("{{.BasePath}}/markdown.css", cssHandler)
+	mux.HandleFunc("{{.BasePath}}/{{.CSSFilename}}", immutable(cssHandler))
 	mux.HandleFunc("{{.BasePath}}/search", searchHandler(index))
+	mux.HandleFunc("{{.BasePath}}/suggest", suggestHandler)
 
 {{- range .Pages}}
 	mux.HandleFunc("{{.WebPath}}", {{.Name}}PageHandler)
@@ -116,9 +204,23 @@ func Handler() http.Handler {
 
 {{- range .StaticFiles}}
 	mux.HandleFunc("{{.Href}}", {{.Name}}StaticFileHandler)
+	mux.HandleFunc("{{.FingerprintedHref}}", immutable({{.Name}}StaticFileHandler))
+{{- end}}
+
+{{- range .GeneratedFiles}}
+	mux.HandleFunc("{{.Href}}", {{.Name}}GeneratedFileHandler)
 {{- end}}
 
-	return mux
+	return mux, nil
+}
+
+// immutable sets the cache headers for fingerprinted files, which
+// will never change.
+func immutable(handler http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Cache-Control", "public, max-age=31536000, immutable")
+		handler(w, req)
+	}
 }
 
 func cssHandler(w http.ResponseWriter, req *http.Request) {
@@ -145,40 +247,40 @@ func {{.Name}}PageHandler(w http.ResponseWriter, req *http.Request) {
 func {{.Name}}StaticFileHandler(w http.ResponseWriter, req *http.Request) {
 	w.Header().Set(contentType, "{{.ContentType}}")
 
+	const content = {{printf "%q" .Content}}
+
+	// nolint: errcheck
+	w.Write([]byte(content))
+}
+{{end}}
+
+{{- range .GeneratedFiles}}
+func {{.Name}}GeneratedFileHandler(w http.ResponseWriter, req *http.Request) {
+	w.Header().Set(contentType, "{{.ContentType}}")
+
+	const content = {{printf "%q" .Content}}
+
 	// nolint: errcheck
-	w.Write([]byte{ {{range .Content}}{{ . }}, {{end}} })
+	w.Write([]byte(content))
 }
 {{end}}
 func searchHandler(searchIndex bleve.Index) func(http.ResponseWriter, *http.Request) {
 	return func(w http.ResponseWriter, req *http.Request) {
 		queryString := req.URL.Query().Get("q")
+		terms := parseSearchTerms(queryString)
 
-		disQuery := bleve.NewDisjunctionQuery()
-
-		for _, q := range strings.Split(queryString, " ") {
-			contentFuzzyQuery := bleve.NewFuzzyQuery(q)
-			contentFuzzyQuery.FieldVal = "Content"
-			contextFuzzyQuery := bleve.NewFuzzyQuery(q)
-			contextFuzzyQuery.FieldVal = "Context"
-
-			contentMatchQuery := bleve.NewMatchQuery(q)
-			contentMatchQuery.FieldVal = "Content"
-			contextMatchQuery := bleve.NewMatchQuery(q)
-			contextMatchQuery.FieldVal = "Context"
+		searchRequest := bleve.NewSearchRequest(buildSearchQuery(terms))
+		searchRequest.Fields = []string{"Context", "HTML", "Link"}
 
-			disQuery.Disjuncts = append(disQuery.Disjuncts,
-				contentFuzzyQuery,
-				contextFuzzyQuery,
-				contentMatchQuery,
-				contextMatchQuery,
-			)
+		for _, name := range filterNames {
+			searchRequest.AddFacet(name, bleve.NewFacetRequest(filterFields[name], maxFacetTerms))
 		}
 
-		searchRequest := bleve.NewSearchRequest(disQuery)
-		searchRequest.Fields = []string{"Context", "HTML", "Link"}
-
-		// nolint: errcheck
-		searchResult, _ := searchIndex.Search(searchRequest)
+		searchResult, err := searchIndex.Search(searchRequest)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
 
 		var result = make([]document, len(searchResult.Hits))
 		for idx, hit := range searchResult.Hits {
@@ -198,34 +300,291 @@ func searchHandler(searchIndex bleve.Index) func(http.ResponseWriter, *http.Requ
 		w.Header().Set(contentType, mimeHTML)
 
 		// nolint: errcheck
-		w.Write(createSearchPage(queryString, result))
+		w.Write(createSearchPage(queryString, createSearchFilters(terms, searchResult.Facets), result))
 	}
 }
 
-func createSearchIndex() (searchIndex bleve.Index, err error) {
-	indexMapping := bleve.NewIndexMapping()
-	if searchIndex, err = bleve.NewMemOnly(indexMapping); err != nil {
- 		return
+const maxSuggestions = 10
+
+type suggestion struct {
+	Title   string ` + "`" + `json:"title"` + "`" + `
+	Link    string ` + "`" + `json:"link"` + "`" + `
+	Context string ` + "`" + `json:"context,omitempty"` + "`" + `
+}
+
+// suggestHandler returns the headings where the title or a word in the
+// title starts with the query, the titles that start with the query
+// are returned first.
+func suggestHandler(w http.ResponseWriter, req *http.Request) {
+	prefix := strings.ToLower(strings.TrimSpace(req.URL.Query().Get("q")))
+
+	var titleMatches, wordMatches = []suggestion{}, []suggestion{}
+
+	for _, s := range suggestions {
+		if prefix == "" {
+			break
+		}
+
+		title := strings.ToLower(s.Title)
+
+		if strings.HasPrefix(title, prefix) {
+			titleMatches = append(titleMatches, s)
+			continue
+		}
+
+		for idx, word := range strings.Fields(title) {
+			if idx > 0 && strings.HasPrefix(word, prefix) {
+				wordMatches = append(wordMatches, s)
+				break
+			}
+		}
 	}
 
-	var doc document
-{{- range .IndexDocuments}}
-	doc = document{
-		Link:    "{{.Link}}",
-		Context: []string{ {{- range $index, $element := .Context}} ` + "`{{$element}}`" + `, {{- end}} },
-		Content: []string{ {{- range $index, $element := .Content}} ` + "`{{$element}}`" + `, {{- end}} },
-		HTML: ` + "`{{.HTML}}`" + `,
+	result := append(titleMatches, wordMatches...)
+	if len(result) > maxSuggestions {
+		result = result[:maxSuggestions]
 	}
 
-	if err = searchIndex.Index("{{.Link}}", doc); err != nil {
-		return
+	w.Header().Set(contentType, mimeJSON)
+
+	// nolint: errcheck
+	json.NewEncoder(w).Encode(result)
+}
+
+var suggestions = []suggestion{
+{{- range .Suggestions}}
+	{Title: {{printf "%q" .Title}}, Link: "{{.Link}}", Context: {{printf "%q" .Context}}},
+{{- end}}
+}
+
+// Boosts used to rank matches in the headings of a section above
+// matches in the text of the section and matches of whole identifiers
+// in code above matches of parts of identifiers.
+const (
+	contextBoost   = 3.0
+	contentBoost   = 1.0
+	codeBoost      = 1.0
+	codePartsBoost = 0.5
+	fuzzyBoost     = 0.3
+)
+
+// fieldAnalyzers are the analyzers of the fields that aren't fields of
+// the documents, i.e. CodeParts is the code analyzed in parts, so the
+// analyzer can't be found by the name of the field in the mapping.
+var fieldAnalyzers = map[string]string{"CodeParts": "code_parts"}
+
+var searchTermRegexp = regexp.MustCompile(` + "`" + `([+-]?)(?:(page|tag):)?(?:"([^"]*)"?|(\S+))` + "`" + `)
+
+const maxFacetTerms = 10
+
+// filterNames are the filters that can be used in the query string,
+// i.e. page:monkey-bar, and filterFields the fields they filter on.
+var filterNames = []string{"page", "tag"}
+var filterFields = map[string]string{"page": "Page", "tag": "Tags"}
+
+type searchTerm struct {
+	text     string
+	filter   string
+	phrase   bool
+	required bool
+	excluded bool
+}
+
+func (term searchTerm) String() (str string) {
+	str = term.text
+
+	if term.phrase {
+		str = "\"" + str + "\""
 	}
-{{end}}
+
+	if term.filter != "" {
+		str = term.filter + ":" + str
+	}
+
+	switch {
+	case term.required:
+		str = "+" + str
+	case term.excluded:
+		str = "-" + str
+	}
+
 	return
 }
 
-func createSearchPage(queryString string, searchResult []document) []byte {
-	var result = "<div><h1>Search result for: \"" + queryString + "\"</h1>"
+// parseSearchTerms splits the query string into terms, where quoted
+// input is a phrase, a term prefixed with + is required, a term
+// prefixed with - is excluded and a term prefixed with a filter name
+// and : is a filter. A quote that isn't closed starts a phrase that
+// ends with the query string.
+func parseSearchTerms(queryString string) (terms []searchTerm) {
+	for _, match := range searchTermRegexp.FindAllStringSubmatch(queryString, -1) {
+		term := searchTerm{
+			text:     match[4],
+			filter:   match[2],
+			phrase:   match[4] == "",
+			required: match[1] == "+",
+			excluded: match[1] == "-",
+		}
+
+		if term.phrase {
+			term.text = match[3]
+		}
+
+		if term.filter != "" {
+			term.text = strings.ToLower(term.text)
+		}
+
+		if strings.TrimSpace(term.text) == "" {
+			continue
+		}
+
+		terms = append(terms, term)
+	}
+
+	return
+}
+
+func joinSearchTerms(terms []searchTerm) string {
+	strs := make([]string, len(terms))
+	for idx, term := range terms {
+		strs[idx] = term.String()
+	}
+
+	return strings.Join(strs, " ")
+}
+
+// buildSearchQuery builds a query where filters on the same field are
+// combined with OR and filters on different fields with AND.
+func buildSearchQuery(terms []searchTerm) query.Query {
+	boolQuery := bleve.NewBooleanQuery()
+	filters := map[string][]query.Query{}
+
+	var onlyExcluded = true
+
+	for _, term := range terms {
+		termQuery := buildTermQuery(term)
+
+		switch {
+		case term.excluded:
+			boolQuery.AddMustNot(termQuery)
+			continue
+		case term.filter != "":
+			filters[term.filter] = append(filters[term.filter], termQuery)
+		case term.required:
+			boolQuery.AddMust(termQuery)
+		default:
+			boolQuery.AddShould(termQuery)
+		}
+
+		onlyExcluded = false
+	}
+
+	for _, name := range filterNames {
+		if len(filters[name]) > 0 {
+			boolQuery.AddMust(bleve.NewDisjunctionQuery(filters[name]...))
+		}
+	}
+
+	if onlyExcluded && boolQuery.MustNot != nil {
+		boolQuery.AddMust(bleve.NewMatchAllQuery())
+	}
+
+	return boolQuery
+}
+
+func buildTermQuery(term searchTerm) query.Query {
+	if term.filter != "" {
+		filterQuery := bleve.NewTermQuery(term.text)
+		filterQuery.SetField(filterFields[term.filter])
+
+		return filterQuery
+	}
+
+	if term.phrase {
+		return bleve.NewDisjunctionQuery(
+			newPhraseQuery(term.text, "Context", contextBoost),
+			newPhraseQuery(term.text, "Content", contentBoost),
+			newPhraseQuery(term.text, "CodeParts", codePartsBoost),
+		)
+	}
+
+	return bleve.NewDisjunctionQuery(
+		newMatchQuery(term.text, "Context", contextBoost, 0),
+		newMatchQuery(term.text, "Content", contentBoost, 0),
+		newMatchQuery(term.text, "Code", codeBoost, 0),
+		newMatchQuery(term.text, "CodeParts", codePartsBoost, 0),
+		newMatchQuery(term.text, "Context", contextBoost*fuzzyBoost, 1),
+		newMatchQuery(term.text, "Content", contentBoost*fuzzyBoost, 1),
+	)
+}
+
+func newPhraseQuery(text, field string, boost float64) query.Query {
+	phraseQuery := bleve.NewMatchPhraseQuery(text)
+	phraseQuery.SetField(field)
+	phraseQuery.SetBoost(boost)
+	phraseQuery.Analyzer = fieldAnalyzers[field]
+
+	return phraseQuery
+}
+
+func newMatchQuery(text, field string, boost float64, fuzziness int) query.Query {
+	matchQuery := bleve.NewMatchQuery(text)
+	matchQuery.SetField(field)
+	matchQuery.SetBoost(boost)
+	matchQuery.SetFuzziness(fuzziness)
+	matchQuery.Analyzer = fieldAnalyzers[field]
+
+	return matchQuery
+}
+
+// searchIndex is the search index built by search-gen, which is opened
+// read-only in memory.
+var searchIndex = search_gen.Index{
+	Mapping: []byte({{printf "%q" .SearchIndex.Mapping}}),
+	Rows:    []byte({{printf "%q" .SearchIndex.Rows}}),
+}
+
+// createSearchFilters renders the facets of the search result as chips,
+// a chip adds its filter to the query or removes it when it is active.
+func createSearchFilters(terms []searchTerm, facets search.FacetResults) (result string) {
+	for _, name := range filterNames {
+		facet, ok := facets[name]
+		if !ok {
+			continue
+		}
+
+		for _, facetTerm := range facet.Terms {
+			filter := searchTerm{text: facetTerm.Term, filter: name}
+			class := "search-filter"
+			chipTerms := append(append([]searchTerm{}, terms...), filter)
+
+			for idx, term := range terms {
+				if term == filter {
+					class += " search-filter-active"
+					chipTerms = append(append([]searchTerm{}, terms[:idx]...), terms[idx+1:]...)
+
+					break
+				}
+			}
+
+			href := "{{.BasePath}}/search?q=" + url.QueryEscape(joinSearchTerms(chipTerms))
+
+			result += "<a class=\"" + class + "\" href=\"" + html.EscapeString(href) + "\">" +
+				html.EscapeString(filter.String()) + " <span>" + strconv.Itoa(facetTerm.Count) + "</span></a>"
+		}
+	}
+
+	if result != "" {
+		result = "<div class=search-filters>" + result + "</div>"
+	}
+
+	return
+}
+
+func createSearchPage(queryString, filters string, searchResult []document) []byte {
+	queryString = html.EscapeString(queryString)
+
+	var result = "<div><h1>Search result for: \"" + queryString + "\"</h1>" + filters
 
 	for _, doc := range searchResult {
 		title := strings.Join(doc.Context, " > ")
@@ -248,7 +607,6 @@ const searchPage = ` + "`" + `{{.SearchPage}}` + "`" + `
 type document struct {
 	Link    string
 	Context []string
-	Content []string
 	HTML    string
 }
 `
@@ -0,0 +1,74 @@
+package gen_test
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lonnblad/go-service-doc/core"
+	go_gen "github.com/lonnblad/go-service-doc/go-pkg-gen"
+)
+
+var suggestionsRegexp = regexp.MustCompile(`(?s)var suggestions = \[\]suggestion\{\n(.*?)\n\}`)
+
+func Test_Build_Suggestions(t *testing.T) {
+	pages := core.Pages{
+		{
+			Name: "bars",
+			Headers: []core.Header{
+				{
+					Title: "Bars",
+					Link:  "/docs#bars",
+					Headers: []core.Header{
+						{Title: "Events", Link: "/docs#events"},
+						{Title: "", Link: "/docs#empty"},
+					},
+				},
+			},
+			IndexDocuments: []core.IndexDocument{
+				{Link: "/docs#bars", Context: []string{"Bars"}},
+				{Link: "/docs#events", Context: []string{"Bars", "Bars", "Events"}},
+				{Link: "/docs#bar-opened", Context: []string{"Bars", "Bars", "Events", `Bar "Opened"`}},
+			},
+		},
+		{
+			Name: "monkeyBar",
+			Headers: []core.Header{
+				{Title: "Monkey Bar", Link: "/docs/monkey-bar#monkey"},
+			},
+			IndexDocuments: []core.IndexDocument{
+				{Link: "/docs/monkey-bar#drinks", Context: []string{"Bars", "Monkey Bar", "Drinks"}},
+			},
+		},
+	}
+
+	content, err := go_gen.New().WithPages(pages).Build()
+	require.NoError(t, err)
+
+	match := suggestionsRegexp.FindSubmatch(content)
+	require.NotNil(t, match)
+
+	// The headings of the menu come first, in the order of the menu,
+	// followed by the headings that are only in the search index.
+	expected := `	{Title: "Bars", Link: "/docs#bars", Context: ""},
+	{Title: "Events", Link: "/docs#events", Context: "Bars"},
+	{Title: "Monkey Bar", Link: "/docs/monkey-bar#monkey", Context: ""},
+	{Title: "Bar \"Opened\"", Link: "/docs#bar-opened", Context: "Bars > Events"},
+	{Title: "Drinks", Link: "/docs/monkey-bar#drinks", Context: "Monkey Bar"},`
+
+	assert.Equal(t, expected, string(match[1]))
+}
+
+func Test_Build_StaticFiles(t *testing.T) {
+	staticFiles := core.Files{
+		{Name: "bar", Href: "/docs/static/bar.png", ContentType: "image/png", Content: []byte("\x89PNG\r\n\"bar\"\x00")},
+	}
+
+	content, err := go_gen.New().WithStaticFiles(staticFiles).Build()
+	require.NoError(t, err)
+
+	// The content is embedded as a string literal, not as a list of bytes.
+	assert.Contains(t, string(content), `const content = "\x89PNG\r\n\"bar\"\x00"`)
+}
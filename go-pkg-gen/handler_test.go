@@ -0,0 +1,92 @@
+package gen_test
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/lonnblad/go-service-doc/exporting/golang"
+	html_gen "github.com/lonnblad/go-service-doc/html-gen"
+	"github.com/lonnblad/go-service-doc/parser"
+	"github.com/lonnblad/go-service-doc/utils"
+)
+
+const handlerBasePath = "/go-service-doc"
+
+// Test_Handler generates the go-handler of the example docs and runs the
+// tests in testdata/docs_test.go against the generated package, so they
+// cover the code generated by the template. The paths that depend on the
+// build are written to fixture_test.go.
+func Test_Handler(t *testing.T) {
+	goCommand, err := exec.LookPath("go")
+	if err != nil {
+		t.Skip("the go command is required to test the generated package")
+	}
+
+	mdParser := parser.NewParser().
+		WithSourceDir("../cmd/example/docs/src").
+		WithBasepath(handlerBasePath).
+		ServiceFilename("bars.md")
+
+	mdParser.Run()
+	require.NoError(t, mdParser.Error())
+
+	// The package is generated inside the module, so it can import
+	// search-gen, and in testdata, so it's ignored by ./...
+	dir, err := ioutil.TempDir("testdata", "handler")
+	require.NoError(t, err)
+
+	// nolint: errcheck
+	defer os.RemoveAll(dir)
+
+	goExporter := golang.NewExporter().
+		WithOutputDir(dir).
+		WithBasepath(handlerBasePath).
+		WithPages(mdParser.Pages()).
+		WithStaticFiles(mdParser.StaticFiles()).
+		WithGeneratedFiles(mdParser.GeneratedFiles()).
+		WithSearchPage(mdParser.SearchPage())
+
+	goExporter.Run()
+	require.NoError(t, goExporter.Error())
+
+	var mermaidScriptHref, faviconHref string
+
+	for _, file := range mdParser.StaticFiles() {
+		switch file.Href {
+		case handlerBasePath + "/static/" + html_gen.MermaidScriptFilename:
+			mermaidScriptHref = file.FingerprintedHref()
+		case handlerBasePath + "/static/favicon.ico":
+			faviconHref = file.FingerprintedHref()
+		}
+	}
+
+	require.NotEmpty(t, mermaidScriptHref)
+	require.NotEmpty(t, faviconHref)
+
+	fixture := fmt.Sprintf(`package docs
+
+const basePath = %q
+
+const cssHref = %q
+
+const mermaidScriptHref = %q
+
+const faviconHref = %q
+`, handlerBasePath, handlerBasePath+"/"+html_gen.GetMarkdownCSSFilename(), mermaidScriptHref, faviconHref)
+
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "fixture_test.go"), []byte(fixture), utils.FilePermission))
+
+	tests, err := ioutil.ReadFile("testdata/docs_test.go")
+	require.NoError(t, err)
+
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "docs_test.go"), tests, utils.FilePermission))
+
+	output, err := exec.Command(goCommand, "test", "./"+filepath.ToSlash(dir)).CombinedOutput()
+	require.NoError(t, err, string(output))
+}
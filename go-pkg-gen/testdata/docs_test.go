@@ -0,0 +1,275 @@
+package docs
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// The tests are run by Test_Handler in go-pkg-gen against the package
+// generated from the example docs, so they cover the code generated by
+// the template. The constants of the fingerprinted hrefs and basePath are
+// set in fixture_test.go.
+
+func Test_Search(t *testing.T) {
+	handler, err := Handler()
+	require.NoError(t, err)
+
+	testcases := []struct {
+		name     string
+		query    string
+		expected string
+	}{
+		{name: "heading", query: "monkey", expected: basePath + "/monkey-bar#monkey"},
+		{name: "stemmed", query: "identifier", expected: basePath + "/donkey-bar#identifiers"},
+		{name: "code identifier", query: "ConvertToKebabCase", expected: basePath + "/donkey-bar#identifiers"},
+		{name: "code part", query: "kebab", expected: basePath + "/donkey-bar#identifiers"},
+		{name: "stemmed code part", query: "serve", expected: basePath + "/donkey-bar#identifiers"},
+	}
+
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			body := get(t, handler, basePath+"/search?q="+url.QueryEscape(tc.query))
+			assert.Contains(t, body, "location.href='"+tc.expected+"'")
+		})
+	}
+}
+
+func Test_ParseSearchTerms(t *testing.T) {
+	testcases := []struct {
+		query    string
+		expected []searchTerm
+	}{
+		{query: "", expected: nil},
+		{query: "monkey bar", expected: []searchTerm{{text: "monkey"}, {text: "bar"}}},
+		{query: `"monkey bar"`, expected: []searchTerm{{text: "monkey bar", phrase: true}}},
+		{query: `+monkey -"donkey bar"`, expected: []searchTerm{{text: "monkey", required: true}, {text: "donkey bar", phrase: true, excluded: true}}},
+		{query: "page:Monkey-Bar -tag:code", expected: []searchTerm{{text: "monkey-bar", filter: "page"}, {text: "code", filter: "tag", excluded: true}}},
+		{query: `tag:"Code"`, expected: []searchTerm{{text: "code", filter: "tag", phrase: true}}},
+		{query: "foo:bar", expected: []searchTerm{{text: "foo:bar"}}},
+		{query: "page:", expected: []searchTerm{{text: "page:"}}},
+		{query: `"monkey bar`, expected: []searchTerm{{text: "monkey bar", phrase: true}}},
+		{query: `donkey "monkey`, expected: []searchTerm{{text: "donkey"}, {text: "monkey", phrase: true}}},
+		{query: `monkey"`, expected: []searchTerm{{text: `monkey"`}}},
+		{query: `"" " "`, expected: nil},
+	}
+
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.query, func(t *testing.T) {
+			assert.Equal(t, tc.expected, parseSearchTerms(tc.query))
+		})
+	}
+}
+
+func Test_JoinSearchTerms(t *testing.T) {
+	query := `+monkey -"donkey bar" page:monkey-bar -tag:code`
+	assert.Equal(t, query, joinSearchTerms(parseSearchTerms(query)))
+}
+
+func Test_Search_Syntax(t *testing.T) {
+	handler, err := Handler()
+	require.NoError(t, err)
+
+	const (
+		monkeyBar    = basePath + "/monkey-bar#monkey"
+		donkeyBar    = basePath + "/donkey-bar#donkey"
+		codeExamples = basePath + "/donkey-bar#code_examples"
+		bars         = basePath + "#bars"
+		table        = basePath + "#table"
+	)
+
+	testcases := []struct {
+		name       string
+		query      string
+		contains   []string
+		excludes   []string
+		onlyPrefix string
+		empty      bool
+	}{
+		{name: "phrase", query: `"code examples"`, contains: []string{codeExamples}},
+		{name: "phrase in the wrong order", query: `"examples code"`, empty: true},
+		{name: "unbalanced quote", query: `"code examples`, contains: []string{codeExamples}},
+		{name: "required", query: "bar +monkey", contains: []string{monkeyBar}, excludes: []string{donkeyBar}},
+		{name: "excluded", query: "bar -donkey", excludes: []string{donkeyBar, monkeyBar, table}},
+		{name: "excluded filter", query: "monkey -page:monkey-bar", contains: []string{table}, excludes: []string{monkeyBar}},
+		{name: "only excluded", query: "-page:donkey-bar", contains: []string{bars}, excludes: []string{donkeyBar}},
+		{name: "page filter", query: "bar page:monkey-bar", contains: []string{monkeyBar}, onlyPrefix: basePath + "/monkey-bar#"},
+		{name: "page filters", query: "monkey donkey page:monkey-bar page:donkey-bar", contains: []string{monkeyBar, donkeyBar}},
+		{name: "tag filter", query: "tag:CODE", contains: []string{donkeyBar}, onlyPrefix: basePath + "/donkey-bar#"},
+		{name: "filters on different fields", query: "tag:code page:monkey-bar", empty: true},
+		{name: "unknown filter is text", query: "foo:monkey", excludes: []string{donkeyBar}},
+	}
+
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			links := searchLinks(t, handler, tc.query)
+
+			for _, link := range tc.contains {
+				assert.Contains(t, links, link)
+			}
+
+			for _, link := range tc.excludes {
+				assert.NotContains(t, links, link)
+			}
+
+			for _, link := range links {
+				if tc.onlyPrefix != "" {
+					assert.True(t, strings.HasPrefix(link, tc.onlyPrefix), link)
+				}
+			}
+
+			assert.Equal(t, tc.empty, len(links) == 0)
+		})
+	}
+}
+
+func Test_Suggest(t *testing.T) {
+	defer func(original []suggestion) { suggestions = original }(suggestions)
+
+	suggestions = []suggestion{
+		{Title: "Monkey Bar", Link: "/docs/monkey-bar#monkey"},
+		{Title: "Bars", Link: "/docs#bars"},
+		{Title: "Opened", Link: "/docs#opened", Context: "Bars > Events"},
+		{Title: "The Bar", Link: "/docs#the-bar"},
+		{Title: "Crowbar", Link: "/docs#crowbar"},
+	}
+
+	testcases := []struct {
+		name     string
+		query    string
+		expected []string
+	}{
+		{name: "empty", query: "", expected: []string{}},
+		{name: "blank", query: "  ", expected: []string{}},
+		{name: "no match", query: "zebra", expected: []string{}},
+		{name: "title before word", query: "bar", expected: []string{"/docs#bars", "/docs/monkey-bar#monkey", "/docs#the-bar"}},
+		{name: "case insensitive", query: " BARS ", expected: []string{"/docs#bars"}},
+		{name: "not in the context", query: "events", expected: []string{}},
+		{name: "not within a word", query: "ened", expected: []string{}},
+	}
+
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, suggestLinks(t, tc.query))
+		})
+	}
+}
+
+func Test_Suggest_Limit(t *testing.T) {
+	defer func(original []suggestion) { suggestions = original }(suggestions)
+
+	suggestions = nil
+
+	for idx := 0; idx < maxSuggestions+5; idx++ {
+		suggestions = append(suggestions, suggestion{Title: fmt.Sprintf("Bar %d", idx), Link: fmt.Sprintf("/docs#bar-%d", idx)})
+	}
+
+	links := suggestLinks(t, "bar")
+	require.Len(t, links, maxSuggestions)
+	assert.Equal(t, "/docs#bar-0", links[0])
+	assert.Equal(t, fmt.Sprintf("/docs#bar-%d", maxSuggestions-1), links[maxSuggestions-1])
+}
+
+func Test_Suggest_Example(t *testing.T) {
+	// The headings of the example docs are embedded with their context.
+	var result []suggestion
+	require.NoError(t, json.Unmarshal([]byte(get(t, http.HandlerFunc(suggestHandler), "/suggest?q=opened")), &result))
+	assert.Equal(t, []suggestion{{Title: "Bar Opened", Link: basePath + "#bar-opened", Context: "Bars > Events"}}, result)
+}
+
+func Test_CacheControl(t *testing.T) {
+	handler, err := Handler()
+	require.NoError(t, err)
+
+	testcases := []struct {
+		name     string
+		target   string
+		expected string
+	}{
+		{name: "fingerprinted css", target: cssHref, expected: "public, max-age=31536000, immutable"},
+		{name: "css", target: basePath + "/markdown.css"},
+		{name: "fingerprinted static file", target: faviconHref, expected: "public, max-age=31536000, immutable"},
+		{name: "static file", target: basePath + "/static/favicon.ico"},
+		{name: "page", target: basePath + "/monkey-bar"},
+	}
+
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, tc.target, nil))
+
+			require.Equal(t, http.StatusOK, recorder.Code)
+			assert.Equal(t, tc.expected, recorder.Header().Get("Cache-Control"))
+		})
+	}
+}
+
+func Test_MermaidScript(t *testing.T) {
+	handler, err := Handler()
+	require.NoError(t, err)
+
+	page := get(t, handler, basePath+"/monkey-bar")
+	assert.Contains(t, page, `<script src="`+mermaidScriptHref+`"></script>`)
+	assert.NotContains(t, get(t, handler, basePath+"/donkey-bar"), "mermaid.min")
+
+	// The bundled Mermaid script is served without a CDN.
+	script := get(t, handler, mermaidScriptHref)
+	assert.Contains(t, script, "mermaidAPI")
+}
+
+// suggestLinks returns the links of the suggestions for the query.
+func suggestLinks(t *testing.T, query string) []string {
+	recorder := httptest.NewRecorder()
+	suggestHandler(recorder, httptest.NewRequest(http.MethodGet, "/suggest?q="+url.QueryEscape(query), nil))
+
+	require.Equal(t, mimeJSON, recorder.Header().Get(contentType))
+
+	var result []suggestion
+	require.NoError(t, json.NewDecoder(recorder.Body).Decode(&result))
+
+	links := []string{}
+	for _, s := range result {
+		links = append(links, s.Link)
+	}
+
+	return links
+}
+
+var searchLinkRegexp = regexp.MustCompile(`location.href='([^']*)'`)
+
+// searchLinks returns the links of the search result of the query.
+func searchLinks(t *testing.T, handler http.Handler, query string) (links []string) {
+	body := get(t, handler, basePath+"/search?q="+url.QueryEscape(query))
+
+	for _, match := range searchLinkRegexp.FindAllStringSubmatch(body, -1) {
+		links = append(links, match[1])
+	}
+
+	return
+}
+
+func get(t *testing.T, handler http.Handler, target string) string {
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, target, nil))
+
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	body, err := ioutil.ReadAll(recorder.Body)
+	require.NoError(t, err)
+
+	return string(body)
+}
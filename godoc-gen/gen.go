@@ -0,0 +1,465 @@
+package gen
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/doc"
+	"go/parser"
+	"go/printer"
+	"go/token"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/pkg/errors"
+
+	"github.com/lonnblad/go-service-doc/utils"
+)
+
+var moduleRegexp = regexp.MustCompile(`(?m)^module\s+"?([^"\s]+)"?\s*$`)
+
+// BuildMarkdown generates the Markdown of a documentation page from the
+// doc comments of the Go package in dir, with the exported constants,
+// variables, functions and types and the examples in the test files.
+func BuildMarkdown(dir string) (_ []byte, err error) {
+	fset := token.NewFileSet()
+
+	pkgs, err := parser.ParseDir(fset, dir, nil, parser.ParseComments)
+	if err != nil {
+		err = errors.Wrap(err, "parser.ParseDir failed")
+		return
+	}
+
+	var (
+		files     []*ast.File
+		testFiles []*ast.File
+		pkgFiles  = map[string]*ast.File{}
+		pkgNames  []string
+	)
+
+	for name, pkg := range pkgs {
+		if !strings.HasSuffix(name, "_test") {
+			pkgNames = append(pkgNames, name)
+		}
+
+		for filename, file := range pkg.Files {
+			files = append(files, file)
+
+			// Test files are only used for the examples.
+			if strings.HasSuffix(filename, "_test.go") {
+				testFiles = append(testFiles, file)
+			} else {
+				pkgFiles[filename] = file
+			}
+		}
+	}
+
+	if len(pkgNames) == 0 {
+		err = errors.Errorf("no Go package found in [%s]", dir)
+		return
+	}
+
+	if len(pkgNames) > 1 {
+		sort.Strings(pkgNames)
+		err = errors.Errorf("multiple Go packages found in [%s], [%s]", dir, strings.Join(pkgNames, ", "))
+		return
+	}
+
+	importPath, err := importPath(dir)
+	if err != nil {
+		err = errors.Wrap(err, "importPath failed")
+		return
+	}
+
+	// The error of ast.NewPackage is about identifiers declared in other
+	// packages, which don't matter to the documentation.
+	astPkg, _ := ast.NewPackage(fset, pkgFiles, nil, nil)
+	pkg := doc.New(astPkg, importPath, 0)
+
+	md := &markdown{fset: fset, examples: classifyExamples(pkg, doc.Examples(testFiles...))}
+
+	for _, file := range files {
+		md.comments = append(md.comments, file.Comments...)
+	}
+
+	md.writePackage(pkg)
+
+	if md.err != nil {
+		return nil, md.err
+	}
+
+	return []byte(md.String()), nil
+}
+
+// importPath returns the import path of the package in dir, from the
+// module path in the go.mod file of the module.
+func importPath(dir string) (_ string, err error) {
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		err = errors.Wrap(err, "filepath.Abs failed")
+		return
+	}
+
+	for moduleDir := absDir; ; moduleDir = filepath.Dir(moduleDir) {
+		content, err := ioutil.ReadFile(filepath.Join(moduleDir, "go.mod"))
+		if err == nil {
+			match := moduleRegexp.FindSubmatch(content)
+			if match == nil {
+				return "", errors.Errorf("module path not found in [%s]", filepath.Join(moduleDir, "go.mod"))
+			}
+
+			rel, _ := filepath.Rel(moduleDir, absDir)
+
+			return strings.TrimSuffix(string(match[1])+"/"+filepath.ToSlash(rel), "/."), nil
+		}
+
+		if !os.IsNotExist(err) {
+			return "", errors.Wrap(err, "ioutil.ReadFile failed")
+		}
+
+		if filepath.Dir(moduleDir) == moduleDir {
+			// Outside of a module, the directory is used as the import path.
+			return filepath.ToSlash(dir), nil
+		}
+	}
+}
+
+type markdown struct {
+	strings.Builder
+	fset     *token.FileSet
+	comments []*ast.CommentGroup
+	examples map[string][]example
+	err      error
+}
+
+// example is an example with the suffix of its name, i.e. monkey for
+// ExampleClient_Bar_monkey.
+type example struct {
+	*doc.Example
+	suffix string
+}
+
+// classifyExamples groups the examples by the name of the package, "",
+// the function, the type or the method, Type_Method, they belong to.
+// Examples that don't belong to an exported name are skipped.
+func classifyExamples(pkg *doc.Package, examples []*doc.Example) map[string][]example {
+	names := map[string]bool{"": true}
+
+	addFuncs := func(funcs []*doc.Func) {
+		for _, fn := range funcs {
+			if token.IsExported(fn.Name) && fn.Level == 0 {
+				names[exampleName(fn)] = true
+			}
+		}
+	}
+
+	addFuncs(pkg.Funcs)
+
+	for _, typ := range pkg.Types {
+		if !token.IsExported(typ.Name) {
+			continue
+		}
+
+		names[typ.Name] = true
+
+		addFuncs(typ.Funcs)
+		addFuncs(typ.Methods)
+	}
+
+	classified := map[string][]example{}
+
+	for _, ex := range examples {
+		// The name is split at the last underscore followed by a lower case
+		// suffix that leaves a known name, or isn't split at all.
+		for idx := len(ex.Name); idx >= 0; idx = strings.LastIndexByte(ex.Name[:idx], '_') {
+			name, suffix := ex.Name, ""
+			if idx < len(ex.Name) {
+				name, suffix = ex.Name[:idx], ex.Name[idx+1:]
+				if suffix == "" || !unicode.IsLower([]rune(suffix)[0]) {
+					continue
+				}
+			}
+
+			if names[name] {
+				classified[name] = append(classified[name], example{Example: ex, suffix: suffix})
+				break
+			}
+		}
+	}
+
+	for _, examples := range classified {
+		sort.SliceStable(examples, func(i, j int) bool {
+			return examples[i].suffix < examples[j].suffix
+		})
+	}
+
+	return classified
+}
+
+// exampleName returns the name that examples of the function use, i.e.
+// Client_Bar for the method Bar of Client.
+func exampleName(fn *doc.Func) string {
+	if fn.Recv == "" {
+		return fn.Name
+	}
+
+	return strings.TrimPrefix(fn.Recv, "*") + "_" + fn.Name
+}
+
+func (md *markdown) line(format string, args ...interface{}) {
+	fmt.Fprintf(md, format+"\n", args...)
+}
+
+func (md *markdown) writePackage(pkg *doc.Package) {
+	md.line("# Package %s {#package-%s}\n", pkg.Name, utils.Slug(pkg.Name))
+	md.line("```go\nimport %q\n```\n", pkg.ImportPath)
+	md.writeDoc(pkg.Doc)
+	md.writeExamples(md.examples[""], "package")
+
+	if len(pkg.Consts) > 0 {
+		md.line("## Constants {#constants}\n")
+		md.writeValues(pkg.Consts)
+	}
+
+	if len(pkg.Vars) > 0 {
+		md.line("## Variables {#variables}\n")
+		md.writeValues(pkg.Vars)
+	}
+
+	for _, fn := range pkg.Funcs {
+		md.writeFunc(fn, 2, "func-"+slug(fn.Name))
+	}
+
+	for _, typ := range pkg.Types {
+		id := "type-" + slug(typ.Name)
+
+		md.line("## type %s {#%s}\n", typ.Name, id)
+		md.writeDecl(typ.Decl)
+		md.writeDoc(typ.Doc)
+		md.writeExamples(md.examples[typ.Name], id)
+		md.writeValues(typ.Consts)
+		md.writeValues(typ.Vars)
+
+		for _, fn := range typ.Funcs {
+			md.writeFunc(fn, 3, "func-"+slug(fn.Name))
+		}
+
+		for _, method := range typ.Methods {
+			md.writeFunc(method, 3, id+"-"+slug(method.Name))
+		}
+	}
+}
+
+func (md *markdown) writeValues(values []*doc.Value) {
+	for _, value := range values {
+		md.writeDecl(value.Decl)
+		md.writeDoc(value.Doc)
+	}
+}
+
+func (md *markdown) writeFunc(fn *doc.Func, level int, id string) {
+	title := "func " + fn.Name
+	if fn.Recv != "" {
+		title = fmt.Sprintf("func (%s) %s", fn.Recv, fn.Name)
+	}
+
+	md.line("%s %s {#%s}\n", strings.Repeat("#", level), title, id)
+
+	decl := *fn.Decl
+	decl.Body = nil
+	md.writeDecl(&decl)
+
+	md.writeDoc(fn.Doc)
+	md.writeExamples(md.examples[exampleName(fn)], id)
+}
+
+// writeDecl writes the declaration as a Go code block, without the doc
+// comment but with the comments inside the declaration, i.e. the comments
+// of the fields of a struct.
+func (md *markdown) writeDecl(decl ast.Decl) {
+	var comments []*ast.CommentGroup
+
+	for _, comment := range md.comments {
+		if comment.Pos() >= decl.Pos() && comment.End() <= decl.End() {
+			comments = append(comments, comment)
+		}
+	}
+
+	switch d := decl.(type) {
+	case *ast.GenDecl:
+		copied := *d
+		copied.Doc = nil
+		decl = &copied
+	case *ast.FuncDecl:
+		copied := *d
+		copied.Doc = nil
+		decl = &copied
+	}
+
+	md.writeCode(&printer.CommentedNode{Node: decl, Comments: comments})
+}
+
+func (md *markdown) writeExamples(examples []example, id string) {
+	for _, example := range examples {
+		title := "Example"
+		if example.suffix != "" {
+			title += " (" + example.suffix + ")"
+		}
+
+		exampleID := id + "-example"
+		if example.suffix != "" {
+			exampleID += "-" + slug(example.suffix)
+		}
+
+		md.line("#### %s {#%s}\n", title, exampleID)
+		md.writeDoc(example.Doc)
+
+		if example.Play != nil {
+			md.writeCode(example.Play)
+		} else {
+			md.writeCode(&printer.CommentedNode{Node: example.Code, Comments: example.Comments})
+		}
+
+		if example.Output != "" {
+			md.line("Output:\n")
+			md.line("```\n%s```\n", example.Output)
+		}
+	}
+}
+
+func (md *markdown) writeCode(node interface{}) {
+	var buffer bytes.Buffer
+
+	config := printer.Config{Mode: printer.UseSpaces | printer.TabIndent, Tabwidth: 4}
+	if err := config.Fprint(&buffer, md.fset, node); err != nil {
+		if md.err == nil {
+			md.err = errors.Wrap(err, "printer.Fprint failed")
+		}
+
+		return
+	}
+
+	code := buffer.String()
+
+	// The code of an example is a block, which is written without the
+	// braces like in godoc.
+	if block, ok := node.(*printer.CommentedNode); ok {
+		if _, isBlock := block.Node.(*ast.BlockStmt); isBlock {
+			code = unindent(strings.TrimSuffix(strings.TrimPrefix(code, "{\n"), "}"))
+		}
+	}
+
+	md.line("```go\n%s\n```\n", strings.TrimRight(code, "\n"))
+}
+
+// writeDoc converts a doc comment to Markdown, paragraphs are written as
+// text, with the Markdown and HTML escaped, and indented blocks as code
+// blocks.
+func (md *markdown) writeDoc(text string) {
+	lines := strings.Split(text, "\n")
+
+	for idx := 0; idx < len(lines); {
+		if isBlank(lines[idx]) {
+			idx++
+			continue
+		}
+
+		start := idx
+
+		if !isIndented(lines[idx]) {
+			for idx < len(lines) && !isBlank(lines[idx]) && !isIndented(lines[idx]) {
+				idx++
+			}
+
+			md.line("%s\n", escapeMarkdown(strings.Join(lines[start:idx], "\n")))
+
+			continue
+		}
+
+		for idx < len(lines) && (isBlank(lines[idx]) || isIndented(lines[idx])) {
+			idx++
+		}
+
+		code := strings.TrimRight(unindent(strings.Join(lines[start:idx], "\n")), "\n")
+		md.line("```\n%s\n```\n", code)
+	}
+}
+
+var (
+	urlRegexp         = regexp.MustCompile(`https?://[^\s<>]*[^\s<>.,:;!?)]`)
+	inlineRegexp      = regexp.MustCompile("[\\\\`*_\\[\\]<>&|~]")
+	blockMarkerRegexp = regexp.MustCompile(`(?m)^(\s*)([#+\-=>:])`)
+	listNumberRegexp  = regexp.MustCompile(`(?m)^(\s*\d+)([.)])`)
+)
+
+// escapeMarkdown escapes the text of a doc comment, so it isn't rendered
+// as Markdown or HTML, i.e. *Client isn't emphasis and <T> isn't a tag.
+// URLs are kept as is to be linked by the autolink extension.
+func escapeMarkdown(text string) string {
+	var escaped strings.Builder
+
+	escape := func(text string) {
+		text = inlineRegexp.ReplaceAllString(text, `\$0`)
+		text = blockMarkerRegexp.ReplaceAllString(text, `$1\$2`)
+		escaped.WriteString(listNumberRegexp.ReplaceAllString(text, `$1\$2`))
+	}
+
+	last := 0
+
+	for _, loc := range urlRegexp.FindAllStringIndex(text, -1) {
+		escape(text[last:loc[0]])
+		escaped.WriteString(text[loc[0]:loc[1]])
+		last = loc[1]
+	}
+
+	escape(text[last:])
+
+	return escaped.String()
+}
+
+func isBlank(line string) bool {
+	return strings.TrimSpace(line) == ""
+}
+
+func isIndented(line string) bool {
+	return strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")
+}
+
+// unindent removes the indentation that is common to all non-empty lines.
+func unindent(code string) string {
+	lines := strings.Split(code, "\n")
+	indent, found := "", false
+
+	for _, line := range lines {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+
+		lineIndent := line[:len(line)-len(strings.TrimLeft(line, " \t"))]
+
+		if !found {
+			indent, found = lineIndent, true
+		}
+
+		for !strings.HasPrefix(lineIndent, indent) {
+			indent = indent[:len(indent)-1]
+		}
+	}
+
+	for idx, line := range lines {
+		lines[idx] = strings.TrimPrefix(line, indent)
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// slug returns the ID of a section of a declaration, i.e. new-bar for
+// NewBar.
+func slug(name string) string {
+	return utils.Slug(utils.ConvertToKebabCase(name))
+}
@@ -0,0 +1,54 @@
+package gen_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	godoc_gen "github.com/lonnblad/go-service-doc/godoc-gen"
+)
+
+func Test_BuildMarkdown(t *testing.T) {
+	md, err := godoc_gen.BuildMarkdown("testdata/bars")
+	require.NoError(t, err)
+
+	for _, expected := range []string{
+		"# Package bars {#package-bars}",
+		"import \"github.com/lonnblad/go-service-doc/godoc-gen/testdata/bars\"",
+		"Package bars is a client for the bars.",
+		"```\nclient := bars.NewClient(\"https://bars.example.com\")\n```",
+		"## Constants {#constants}\n\n```go\nconst DefaultURL = \"https://bars.example.com\"\n```",
+		"## func Kind {#func-kind}\n\n```go\nfunc Kind(name string) string\n```",
+		"## type Client {#type-client}",
+		"\t// URL is the URL of the service.\n\tURL string\n\t// contains filtered or unexported fields",
+		"### func NewClient {#func-new-client}",
+		"### func (*Client) Bar {#type-client-bar}",
+		"#### Example {#type-client-bar-example}",
+		"Output:\n\n```\nmonkey\n```",
+		"#### Example (donkey) {#type-client-bar-example-donkey}",
+		"#### Example {#func-kind-example}",
+		"#### Example {#package-example}",
+		"Output:\n\n```\nhttps://bars.example.com\n```",
+	} {
+		assert.Contains(t, string(md), expected)
+	}
+
+	assert.NotContains(t, string(md), "func kind")
+
+	// The doc comments are escaped, except for the URLs.
+	assert.Contains(t, string(md), "NewClient returns a \\*Client for the service at url, see\nhttps://bars.example.com/docs_v2 for the \\<url\\> of a service.")
+}
+
+func Test_BuildMarkdown_MissingPackage(t *testing.T) {
+	_, err := godoc_gen.BuildMarkdown("testdata/missing")
+	assert.Error(t, err)
+
+	_, err = godoc_gen.BuildMarkdown("testdata")
+	assert.Error(t, err)
+}
+
+func Test_BuildMarkdown_MultiplePackages(t *testing.T) {
+	_, err := godoc_gen.BuildMarkdown("testdata/multiple")
+	assert.EqualError(t, err, "multiple Go packages found in [testdata/multiple], [donkey, monkey]")
+}
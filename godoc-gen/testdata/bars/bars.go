@@ -0,0 +1,36 @@
+// Package bars is a client for the bars.
+//
+// Create a client with the URL of the service:
+//
+//	client := bars.NewClient("https://bars.example.com")
+package bars
+
+// DefaultURL is the URL of the service.
+const DefaultURL = "https://bars.example.com"
+
+// Client is a client for the bars.
+type Client struct {
+	// URL is the URL of the service.
+	URL   string
+	token string
+}
+
+// NewClient returns a *Client for the service at url, see
+// https://bars.example.com/docs_v2 for the <url> of a service.
+func NewClient(url string) *Client {
+	return &Client{URL: url}
+}
+
+// Bar returns the name of the bar.
+func (c *Client) Bar(id string) string {
+	return id
+}
+
+// Kind returns the kind of a bar.
+func Kind(name string) string {
+	return kind(name)
+}
+
+func kind(name string) string {
+	return name
+}
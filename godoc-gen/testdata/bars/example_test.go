@@ -0,0 +1,30 @@
+package bars_test
+
+import (
+	"fmt"
+
+	"github.com/lonnblad/go-service-doc/godoc-gen/testdata/bars"
+)
+
+func ExampleClient_Bar() {
+	client := bars.NewClient(bars.DefaultURL)
+	fmt.Println(client.Bar("monkey"))
+	// Output: monkey
+}
+
+func ExampleClient_Bar_donkey() {
+	client := bars.NewClient(bars.DefaultURL)
+	fmt.Println(client.Bar("donkey"))
+	// Output: donkey
+}
+
+func ExampleKind() {
+	fmt.Println(bars.Kind("monkey"))
+	// Output: monkey
+}
+
+func Example() {
+	client := bars.NewClient(bars.DefaultURL)
+	fmt.Println(client.URL)
+	// Output: https://bars.example.com
+}
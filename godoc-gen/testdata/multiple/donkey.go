@@ -0,0 +1,2 @@
+// Package donkey is a bar.
+package donkey
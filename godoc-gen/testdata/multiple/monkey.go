@@ -0,0 +1,2 @@
+// Package monkey is a bar.
+package monkey
@@ -2,21 +2,42 @@ package gen
 
 import (
 	"bytes"
+	"strings"
 	"text/template"
+	"time"
 
 	"github.com/pkg/errors"
 
 	"github.com/lonnblad/go-service-doc/core"
+	"github.com/lonnblad/go-service-doc/utils"
+)
+
+const (
+	markdownCSSFilename = "markdown.css"
+
+	// MermaidScriptFilename is the filename of the bundled Mermaid script.
+	MermaidScriptFilename = "mermaid.min.js"
 )
 
 type Gen struct {
-	api         string
-	pages       core.Pages
-	doc         string
-	searchLink  string
-	queryString string
-	basepath    string
-	faviconHref string
+	api          string
+	pages        core.Pages
+	doc          string
+	searchLink   string
+	suggestLink  string
+	queryString  string
+	basepath     string
+	faviconHref  string
+	mermaidHref  string
+	feedTitle    string
+	feedHref     string
+	canonical    string
+	title        string
+	description  string
+	image        string
+	updated      time.Time
+	updatedBy    string
+	contributors []string
 }
 
 func New() *Gen {
@@ -43,6 +64,11 @@ func (g *Gen) WithSearchLink(searchLink string) *Gen {
 	return g
 }
 
+func (g *Gen) WithSuggestLink(suggestLink string) *Gen {
+	g.suggestLink = suggestLink
+	return g
+}
+
 func (g *Gen) WithBasepath(basepath string) *Gen {
 	g.basepath = basepath
 	return g
@@ -53,23 +79,105 @@ func (g *Gen) WithFavicon(href string) *Gen {
 	return g
 }
 
+// WithMermaidScript adds the Mermaid script to the page and initializes
+// it to draw the diagrams of the page.
+func (g *Gen) WithMermaidScript(href string) *Gen {
+	g.mermaidHref = href
+	return g
+}
+
+// WithFeed adds a link to an Atom feed, which lets feed readers find
+// the feed from the page.
+func (g *Gen) WithFeed(title, href string) *Gen {
+	g.feedTitle = title
+	g.feedHref = href
+
+	return g
+}
+
+// WithPageMeta sets the title, the description and the preview image of
+// the page, which are used in the title of the page and in the meta tags
+// for search engines and link previews.
+func (g *Gen) WithPageMeta(title, description, image string) *Gen {
+	g.title = title
+	g.description = description
+	g.image = image
+
+	return g
+}
+
+// WithCanonicalURL adds the absolute URL of the page as the canonical
+// URL, which search engines use for the page when it's reachable from
+// several URLs.
+func (g *Gen) WithCanonicalURL(url string) *Gen {
+	g.canonical = url
+	return g
+}
+
+// WithHistory adds a footer with when the page was last changed, by whom
+// and the contributors to it. The footer is left out if the author of
+// the last change is unknown.
+func (g *Gen) WithHistory(updated time.Time, updatedBy string, contributors []string) *Gen {
+	g.updated = updated
+	g.updatedBy = updatedBy
+	g.contributors = contributors
+
+	return g
+}
+
 func (g *Gen) Build() (_ []byte, err error) {
+	title := g.api
+	if g.title != "" && g.title != g.api {
+		title = g.title + " – " + g.api
+	}
+
 	templateInfo := struct {
-		API         string
-		Pages       core.Pages
-		Doc         string
-		SearchLink  string
-		QueryString string
-		Basepath    string
-		FaviconHref string
+		API          string
+		Title        string
+		PageTitle    string
+		Description  string
+		Image        string
+		Pages        core.Pages
+		Doc          string
+		SearchLink   string
+		SuggestLink  string
+		QueryString  string
+		Basepath     string
+		CSSFilename  string
+		FaviconHref  string
+		MermaidHref  string
+		FeedTitle    string
+		FeedHref     string
+		Canonical    string
+		Updated      string
+		UpdatedAt    string
+		UpdatedBy    string
+		Contributors string
 	}{
-		API:         g.api,
-		Pages:       g.pages,
-		Doc:         g.doc,
-		SearchLink:  g.searchLink,
-		QueryString: g.queryString,
-		Basepath:    g.basepath,
-		FaviconHref: g.faviconHref,
+		API:          g.api,
+		Title:        title,
+		PageTitle:    g.title,
+		Description:  g.description,
+		Image:        g.image,
+		Pages:        g.pages,
+		Doc:          g.doc,
+		SearchLink:   g.searchLink,
+		SuggestLink:  g.suggestLink,
+		QueryString:  g.queryString,
+		Basepath:     g.basepath,
+		CSSFilename:  GetMarkdownCSSFilename(),
+		FaviconHref:  g.faviconHref,
+		MermaidHref:  g.mermaidHref,
+		FeedTitle:    g.feedTitle,
+		FeedHref:     g.feedHref,
+		Canonical:    g.canonical,
+		UpdatedBy:    g.updatedBy,
+		Contributors: strings.Join(g.contributors, ", "),
+	}
+
+	if g.updatedBy != "" {
+		templateInfo.Updated = g.updated.UTC().Format("January 2, 2006")
+		templateInfo.UpdatedAt = g.updated.UTC().Format(time.RFC3339)
 	}
 
 	generator, err := template.New("html_page").Parse(htmlPageTemplate)
@@ -98,14 +206,36 @@ func GetMarkdownCSS() []byte {
 	return []byte(markdownCSS)
 }
 
+// GetMarkdownCSSFilename returns the filename of the CSS with the
+// fingerprint of the content, i.e. markdown.8b1d2e.css.
+func GetMarkdownCSSFilename() string {
+	return utils.AddFingerprint(markdownCSSFilename, utils.Fingerprint([]byte(markdownCSS)))
+}
+
+// GetMermaidScript returns the bundled Mermaid script, which draws the
+// Mermaid diagrams in the browser.
+func GetMermaidScript() []byte {
+	return []byte(mermaidScript)
+}
+
 // nolint: lll
 const htmlPageTemplate = `<!DOCTYPE html>
 <html lang=en>
 <head>
-  <title>{{.API}}</title>
+  <title>{{html .Title}}</title>
   <meta name='generator' content='github.com/lonnblad/go-service-doc'>
-  <link rel="stylesheet" href="{{.Basepath}}/markdown.css">
+  {{if .Description}}<meta name="description" content="{{html .Description}}">{{end}}
+  {{if .PageTitle}}<meta property="og:type" content="website">
+  <meta property="og:site_name" content="{{html .API}}">
+  <meta property="og:title" content="{{html .PageTitle}}">
+  {{if .Description}}<meta property="og:description" content="{{html .Description}}">{{end}}
+  {{if .Canonical}}<meta property="og:url" content="{{.Canonical}}">{{end}}
+  {{if .Image}}<meta property="og:image" content="{{.Image}}">{{end}}
+  <meta name="twitter:card" content="{{if .Image}}summary_large_image{{else}}summary{{end}}">{{end}}
+  <link rel="stylesheet" href="{{.Basepath}}/{{.CSSFilename}}">
   {{if .FaviconHref}}<link rel="icon" href="{{.FaviconHref}}">{{end}}
+  {{if .Canonical}}<link rel="canonical" href="{{.Canonical}}">{{end}}
+  {{if .FeedHref}}<link rel="alternate" type="application/atom+xml" title="{{html .FeedTitle}}" href="{{.FeedHref}}">{{end}}
 </head>
 <body class="markdown-body">
   <div class="flex-container">
@@ -115,7 +245,8 @@ const htmlPageTemplate = `<!DOCTYPE html>
         <form class=menu-search action="{{.Basepath}}{{.SearchLink}}" method="get">
           <input type="text" placeholder="Search.." name="q" value="{{.QueryString}}" onfocus="var temp_value=this.value; this.value=''; this.value=temp_value" autofocus />
           <button type="submit">Search</button>
-        </form>
+        </form>{{if .SuggestLink}}
+        <div class=menu-suggestions></div>{{end}}
       </div>
       <div class=menu-content>
         <ul>{{range .Pages}}{{range .Headers}}
@@ -128,8 +259,92 @@ const htmlPageTemplate = `<!DOCTYPE html>
       </div>
     </div>
     <div class="doc-container">
-      {{.Doc}}
+      {{.Doc}}{{if .UpdatedBy}}
+      <footer class="doc-footer">
+        <p>Last updated on <time datetime="{{.UpdatedAt}}">{{.Updated}}</time> by {{html .UpdatedBy}}.</p>
+        <p>Contributors: {{html .Contributors}}</p>
+      </footer>{{end}}
     </div>
-  </div>
+  </div>{{if .SuggestLink}}
+  <script>
+    (function () {
+      var input = document.querySelector('.menu-search input[name=q]');
+      var list = document.querySelector('.menu-suggestions');
+      var selected = -1;
+      var timer;
+
+      function render(suggestions) {
+        list.innerHTML = '';
+        selected = -1;
+
+        suggestions.forEach(function (suggestion) {
+          var link = document.createElement('a');
+          link.href = suggestion.link;
+          link.textContent = suggestion.title;
+
+          if (suggestion.context) {
+            var context = document.createElement('span');
+            context.textContent = suggestion.context;
+            link.appendChild(context);
+          }
+
+          list.appendChild(link);
+        });
+      }
+
+      input.addEventListener('input', function () {
+        var query = input.value.trim();
+
+        clearTimeout(timer);
+
+        if (query === '') {
+          render([]);
+          return;
+        }
+
+        timer = setTimeout(function () {
+          fetch('{{.Basepath}}{{.SuggestLink}}?q=' + encodeURIComponent(query))
+            .then(function (resp) { return resp.ok ? resp.json() : []; })
+            .then(render)
+            .catch(function () { render([]); });
+        }, 150);
+      });
+
+      input.addEventListener('keydown', function (event) {
+        var items = list.getElementsByTagName('a');
+
+        if (event.key === 'Escape') {
+          render([]);
+          return;
+        }
+
+        if (event.key === 'Enter' && selected >= 0) {
+          event.preventDefault();
+          location.href = items[selected].href;
+          return;
+        }
+
+        if ((event.key !== 'ArrowDown' && event.key !== 'ArrowUp') || items.length === 0) {
+          return;
+        }
+
+        event.preventDefault();
+
+        if (selected >= 0) {
+          items[selected].classList.remove('selected');
+        }
+
+        if (event.key === 'ArrowDown') {
+          selected = (selected + 1) % items.length;
+        } else {
+          selected = (selected <= 0 ? items.length : selected) - 1;
+        }
+
+        items[selected].classList.add('selected');
+      });
+    })();
+  </script>{{end}}{{if .MermaidHref}}
+  <script src="{{.MermaidHref}}"></script>
+  <script>mermaid.initialize({ startOnLoad: true });</script>{{end}}
 </body>
 </html>`
@@ -55,6 +55,28 @@ const markdownCSS = `@font-face {
   background: #ccc;
 }
 
+.menu-suggestions {
+  display: flex;
+  flex-direction: column;
+}
+
+.menu-suggestions a {
+  padding: 4px 10px;
+  border-bottom: 1px solid #eaecef;
+  color: #24292e;
+}
+
+.menu-suggestions a span {
+  display: block;
+  font-size: 12px;
+  color: #6a737d;
+}
+
+.menu-suggestions a:hover, .menu-suggestions a.selected {
+  background-color: #eaecef;
+  text-decoration: none;
+}
+
 .menu-content {
   overflow: auto;
 }
@@ -84,6 +106,18 @@ const markdownCSS = `@font-face {
   display: block;
 }
 
+.markdown-body .doc-container .doc-footer {
+  margin-top: 32px;
+  padding-top: 8px;
+  border-top: 1px solid #eaecef;
+  color: #6a737d;
+  font-size: 85%;
+}
+
+.markdown-body .doc-container .doc-footer p {
+  margin: 0;
+}
+
 .markdown-body .doc-container .search-result-card {
   box-shadow: 0 4px 8px 0 rgba(0,0,0,0.2);
   transition: 0.3s;
@@ -101,6 +135,113 @@ const markdownCSS = `@font-face {
   padding: 0em 0.8em;
 }
 
+.markdown-body .doc-container .search-filters {
+  display: flex;
+  flex-wrap: wrap;
+  margin-bottom: 1em;
+}
+
+.markdown-body .doc-container .search-filters .search-filter {
+  padding: 0.2em 0.8em;
+  margin: 0 0.5em 0.5em 0;
+  border: 1px solid #e1e4e8;
+  border-radius: 1em;
+  background-color: #f6f8fa;
+  color: #24292e;
+  font-size: 85%;
+}
+
+.markdown-body .doc-container .search-filters .search-filter:hover {
+  background-color: #eaecef;
+  text-decoration: none;
+}
+
+.markdown-body .doc-container .search-filters .search-filter span {
+  color: #6a737d;
+}
+
+.markdown-body .doc-container .search-filters .search-filter-active {
+  border-color: #0366d6;
+  background-color: #0366d6;
+  color: #fff;
+}
+
+.markdown-body .doc-container .search-filters .search-filter-active span {
+  color: #fff;
+}
+
+.markdown-body .mermaid {
+  margin-bottom: 16px;
+  overflow: auto;
+  text-align: center;
+}
+
+.markdown-body .callout {
+  padding: 8px 16px;
+  margin-bottom: 16px;
+  border-left: 0.25em solid;
+  border-radius: 3px;
+}
+
+.markdown-body .callout > :last-child {
+  margin-bottom: 0;
+}
+
+.markdown-body .callout .callout-title {
+  display: flex;
+  align-items: center;
+  margin-bottom: 8px;
+  font-weight: 600;
+}
+
+.markdown-body .callout .callout-title::before {
+  content: "";
+  flex-shrink: 0;
+  width: 16px;
+  height: 16px;
+  margin-right: 8px;
+  background-repeat: no-repeat;
+}
+
+.markdown-body .callout-note {
+  border-color: #0366d6;
+  background-color: #f1f8ff;
+}
+
+.markdown-body .callout-note .callout-title {
+  color: #0366d6;
+}
+
+.markdown-body .callout-note .callout-title::before {
+  background-image: url("data:image/svg+xml,%3Csvg xmlns='http://www.w3.org/2000/svg' viewBox='0 0 16 16'%3E%3Ccircle cx='8' cy='8' r='7.5' fill='%230366d6'/%3E%3Ccircle cx='8' cy='4.5' r='1' fill='%23fff'/%3E%3Crect x='7' y='7' width='2' height='5.5' rx='1' fill='%23fff'/%3E%3C/svg%3E");
+}
+
+.markdown-body .callout-warning {
+  border-color: #b08800;
+  background-color: #fffbdd;
+}
+
+.markdown-body .callout-warning .callout-title {
+  color: #735c0f;
+}
+
+.markdown-body .callout-warning .callout-title::before {
+  background-image: url("data:image/svg+xml,%3Csvg xmlns='http://www.w3.org/2000/svg' viewBox='0 0 16 16'%3E%3Cpath d='M8 0.5L15.5 15H0.5Z' fill='%23b08800'/%3E%3Crect x='7' y='5' width='2' height='5.5' rx='1' fill='%23fff'/%3E%3Ccircle cx='8' cy='12.5' r='1' fill='%23fff'/%3E%3C/svg%3E");
+}
+
+.markdown-body .callout-danger {
+  border-color: #d73a49;
+  background-color: #ffeef0;
+}
+
+.markdown-body .callout-danger .callout-title {
+  color: #cb2431;
+}
+
+.markdown-body .callout-danger .callout-title::before {
+  background-image: url("data:image/svg+xml,%3Csvg xmlns='http://www.w3.org/2000/svg' viewBox='0 0 16 16'%3E%3Cpath d='M4.7 0.5H11.3L15.5 4.7V11.3L11.3 15.5H4.7L0.5 11.3V4.7Z' fill='%23d73a49'/%3E%3Crect x='7' y='3.5' width='2' height='6' rx='1' fill='%23fff'/%3E%3Ccircle cx='8' cy='12' r='1' fill='%23fff'/%3E%3C/svg%3E");
+}
+
 .markdown-body .octicon {
   display: inline-block;
   fill: currentColor;
@@ -770,6 +911,26 @@ const markdownCSS = `@font-face {
   margin: 0 .2em .25em -1.6em;
   vertical-align: middle;
 }
+.markdown-body .footnotes {
+  font-size: 85%;
+  color: #6a737d;
+}
+.markdown-body .footnotes hr {
+  margin: 24px 0 16px;
+}
+.markdown-body .footnotes ol {
+  padding-left: 16px;
+}
+.markdown-body .footnotes li {
+  margin-top: 8px;
+}
+.markdown-body .footnote-ref, .markdown-body .footnotes .footnote-backref {
+  padding: 0 2px;
+  text-decoration: none;
+}
+.markdown-body .footnotes li:target {
+  color: #24292e;
+}
 .markdown-body hr {
   border-bottom-color: #eee;
 }
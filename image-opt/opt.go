@@ -0,0 +1,117 @@
+package opt
+
+import (
+	"bytes"
+	"image"
+	"image/jpeg"
+	"image/png"
+
+	"github.com/pkg/errors"
+	"golang.org/x/image/draw"
+)
+
+const (
+	mimePNG  = "image/png"
+	mimeJPEG = "image/jpeg"
+
+	jpegQuality = 85
+)
+
+// VariantWidths are the widths of the downscaled variants, variants are
+// only generated for widths smaller than the width of the image.
+var VariantWidths = []int{480, 960, 1440}
+
+type Image struct {
+	Content []byte
+	Width   int
+	Height  int
+}
+
+type Result struct {
+	Image
+	Variants []Image
+}
+
+// Supported returns true if images of the content type can be optimized.
+func Supported(contentType string) bool {
+	return contentType == mimePNG || contentType == mimeJPEG
+}
+
+// Optimize recompresses a PNG or JPEG image, the original content is kept
+// if it is smaller, and generates downscaled variants of the image.
+func Optimize(content []byte, contentType string) (result Result, err error) {
+	if !Supported(contentType) {
+		err = errors.Errorf("unsupported content type, [%s]", contentType)
+		return
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(content))
+	if err != nil {
+		err = errors.Wrap(err, "image.Decode failed")
+		return
+	}
+
+	bounds := img.Bounds()
+	result.Width = bounds.Dx()
+	result.Height = bounds.Dy()
+
+	if result.Content, err = encode(img, contentType); err != nil {
+		return
+	}
+
+	if len(content) <= len(result.Content) {
+		result.Content = content
+	}
+
+	for _, width := range VariantWidths {
+		if width >= result.Width {
+			continue
+		}
+
+		var variant Image
+
+		if variant, err = resize(img, contentType, width); err != nil {
+			return
+		}
+
+		result.Variants = append(result.Variants, variant)
+	}
+
+	return result, nil
+}
+
+func resize(img image.Image, contentType string, width int) (variant Image, err error) {
+	bounds := img.Bounds()
+
+	variant.Width = width
+	variant.Height = bounds.Dy() * width / bounds.Dx()
+
+	if variant.Height == 0 {
+		variant.Height = 1
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, variant.Width, variant.Height))
+	draw.CatmullRom.Scale(dst, dst.Bounds(), img, bounds, draw.Src, nil)
+
+	variant.Content, err = encode(dst, contentType)
+
+	return
+}
+
+func encode(img image.Image, contentType string) (_ []byte, err error) {
+	buffer := &bytes.Buffer{}
+
+	if contentType == mimePNG {
+		encoder := png.Encoder{CompressionLevel: png.BestCompression}
+		err = encoder.Encode(buffer, img)
+	} else {
+		err = jpeg.Encode(buffer, img, &jpeg.Options{Quality: jpegQuality})
+	}
+
+	if err != nil {
+		err = errors.Wrap(err, "failed to encode image")
+		return
+	}
+
+	return buffer.Bytes(), nil
+}
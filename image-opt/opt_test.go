@@ -0,0 +1,49 @@
+package opt_test
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/png"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	image_opt "github.com/lonnblad/go-service-doc/image-opt"
+)
+
+func Test_Optimize(t *testing.T) {
+	img := image.NewNRGBA(image.Rect(0, 0, 1000, 500))
+	for x := 0; x < 1000; x++ {
+		for y := 0; y < 500; y++ {
+			img.Set(x, y, color.NRGBA{R: uint8(x / 4), G: uint8(y / 2), B: 100, A: 255})
+		}
+	}
+
+	buffer := &bytes.Buffer{}
+	encoder := png.Encoder{CompressionLevel: png.NoCompression}
+	require.NoError(t, encoder.Encode(buffer, img))
+
+	result, err := image_opt.Optimize(buffer.Bytes(), "image/png")
+	require.NoError(t, err)
+
+	assert.Equal(t, 1000, result.Width)
+	assert.Equal(t, 500, result.Height)
+	assert.Less(t, len(result.Content), buffer.Len())
+
+	require.Len(t, result.Variants, 2)
+	assert.Equal(t, 480, result.Variants[0].Width)
+	assert.Equal(t, 240, result.Variants[0].Height)
+	assert.Equal(t, 960, result.Variants[1].Width)
+	assert.Equal(t, 480, result.Variants[1].Height)
+
+	variant, _, err := image.Decode(bytes.NewReader(result.Variants[0].Content))
+	require.NoError(t, err)
+	assert.Equal(t, 480, variant.Bounds().Dx())
+}
+
+func Test_Optimize_UnsupportedContentType(t *testing.T) {
+	_, err := image_opt.Optimize([]byte("<svg></svg>"), "image/svg+xml")
+	assert.Error(t, err)
+}
@@ -0,0 +1,496 @@
+package gen
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/lonnblad/go-service-doc/utils"
+)
+
+// BuildMarkdown generates Markdown tables with the properties of the JSON
+// Schema at path, one for the schema and one for each nested object, and
+// an example payload. References are resolved within rootDir.
+func BuildMarkdown(rootDir, path string) (_ []byte, err error) {
+	r := newResolver(rootDir)
+
+	root, err := r.load(path)
+	if err != nil {
+		err = errors.Wrap(err, "resolver.load failed")
+		return
+	}
+
+	md := &markdown{resolver: r}
+	md.writeSchema(root)
+
+	if md.err != nil {
+		return nil, md.err
+	}
+
+	return []byte(md.String()), nil
+}
+
+type markdown struct {
+	strings.Builder
+	resolver *resolver
+	err      error
+}
+
+// object is an object schema to write a property table for, name is the
+// path of the object in the payload, i.e. location or tags[], and refs
+// are the references it was resolved through, used to break cycles.
+type object struct {
+	name   string
+	schema *schema
+	refs   []string
+}
+
+func (md *markdown) line(format string, args ...interface{}) {
+	fmt.Fprintf(md, format+"\n", args...)
+}
+
+func (md *markdown) writeSchema(root *schema) {
+	resolved := md.resolve(root)
+	if resolved == nil {
+		return
+	}
+
+	if resolved.Description != "" {
+		md.line("%s\n", strings.TrimSpace(resolved.Description))
+	}
+
+	objects := []object{{schema: resolved}}
+
+	for len(objects) > 0 {
+		obj := objects[0]
+		objects = objects[1:]
+
+		objects = append(objects, md.writeProperties(obj)...)
+	}
+
+	if example := md.exampleValue(root, nil); example != nil {
+		bs, err := json.MarshalIndent(example, "", "  ")
+		if err != nil {
+			md.fail(errors.Wrap(err, "json.MarshalIndent failed"))
+			return
+		}
+
+		md.line("Example payload:\n")
+		md.line("```json\n%s\n```", bs)
+	}
+}
+
+// writeProperties writes a table with the properties of an object and
+// returns the nested objects of the properties, which get their own
+// tables.
+func (md *markdown) writeProperties(obj object) (nested []object) {
+	props := md.objectProperties(obj.schema, 0)
+	if len(props) == 0 {
+		return
+	}
+
+	required := map[string]bool{}
+	for _, name := range md.requiredProperties(obj.schema) {
+		required[name] = true
+	}
+
+	if obj.name != "" {
+		md.line("Properties of `%s`:\n", obj.name)
+	}
+
+	md.line("| Property | Type | Required | Description |")
+	md.line("| -------- | ---- | -------- | ----------- |")
+
+	for _, prop := range props {
+		md.line("| `%s` | %s | %s | %s |",
+			prop.name, md.typeName(prop.schema), yesNo(required[prop.name]), utils.EscapeTableCell(md.description(prop.schema)))
+
+		name := prop.name
+		if obj.name != "" {
+			name = obj.name + "." + prop.name
+		}
+
+		if child, ok := md.nestedObject(name, prop.schema, obj.refs); ok {
+			nested = append(nested, child)
+		}
+	}
+
+	md.line("")
+
+	return nested
+}
+
+// nestedObject returns the object of a property, or of the items of an
+// array property, if it has properties and isn't one of the objects it's
+// nested in.
+func (md *markdown) nestedObject(name string, sc *schema, refs []string) (_ object, ok bool) {
+	if len(refs) > maxRefDepth {
+		return
+	}
+
+	resolved, refs := md.resolveWithRefs(sc, refs)
+	if resolved == nil {
+		return
+	}
+
+	if resolved.Type.is("array") && resolved.Items != nil {
+		return md.nestedObject(name+"[]", resolved.Items, refs)
+	}
+
+	for _, ref := range refs[:len(refs)-1] {
+		if ref == refs[len(refs)-1] && ref != "" {
+			return
+		}
+	}
+
+	if len(md.objectProperties(resolved, 0)) == 0 {
+		return
+	}
+
+	return object{name: name, schema: resolved, refs: refs}, true
+}
+
+// resolveWithRefs resolves a schema and appends the reference it was
+// resolved through to refs, or an empty string if it isn't a reference.
+func (md *markdown) resolveWithRefs(sc *schema, refs []string) (*schema, []string) {
+	ref := ""
+	if sc != nil && sc.Ref != "" {
+		ref = sc.file + "#" + sc.Ref
+	}
+
+	return md.resolve(sc), append(append([]string{}, refs...), ref)
+}
+
+func (md *markdown) resolve(sc *schema) *schema {
+	resolved, err := md.resolver.resolve(sc)
+	if err != nil {
+		md.fail(errors.Wrap(err, "resolver.resolve failed"))
+		return nil
+	}
+
+	return resolved
+}
+
+func (md *markdown) fail(err error) {
+	if md.err == nil {
+		md.err = err
+	}
+}
+
+// objectProperties returns the properties of a schema, including the
+// properties of the schemas in allOf.
+func (md *markdown) objectProperties(sc *schema, depth int) (props properties) {
+	sc = md.resolve(sc)
+	if sc == nil || depth > maxRefDepth {
+		return
+	}
+
+	for _, sub := range sc.AllOf {
+		props = append(props, md.objectProperties(sub, depth+1)...)
+	}
+
+	return append(props, sc.Properties...)
+}
+
+func (md *markdown) requiredProperties(sc *schema) []string {
+	required := append([]string{}, sc.Required...)
+
+	for _, sub := range sc.AllOf {
+		if sub = md.resolve(sub); sub != nil {
+			required = append(required, sub.Required...)
+		}
+	}
+
+	return required
+}
+
+// typeName returns the type of a schema, referenced objects are named by
+// their title or the name of the reference.
+func (md *markdown) typeName(sc *schema) string {
+	if sc == nil {
+		return ""
+	}
+
+	if sc.Ref != "" {
+		resolved := md.resolve(sc)
+		if resolved == nil {
+			return ""
+		}
+
+		if len(md.objectProperties(resolved, 0)) == 0 {
+			return md.typeName(resolved)
+		}
+
+		if resolved.Title != "" {
+			return resolved.Title
+		}
+
+		return refName(sc.Ref)
+	}
+
+	var (
+		name     string
+		nullable = sc.Type.is("null")
+	)
+
+	switch {
+	case len(sc.AllOf) > 0:
+		name = md.typeNames(sc.AllOf, " & ")
+	case len(sc.OneOf) > 0:
+		name = md.typeNames(sc.OneOf, " \\| ")
+	case len(sc.AnyOf) > 0:
+		name = md.typeNames(sc.AnyOf, " \\| ")
+	default:
+		var types []string
+
+		for _, typ := range sc.Type {
+			switch typ {
+			case "null":
+			case "array":
+				types = append(types, "[]"+md.typeName(sc.Items))
+			default:
+				types = append(types, typ)
+			}
+		}
+
+		name = strings.Join(types, " \\| ")
+	}
+
+	switch {
+	case name == "" && len(sc.Properties) > 0:
+		name = "object"
+	case name == "":
+		name = "any"
+	}
+
+	if sc.Format != "" {
+		name += " (" + sc.Format + ")"
+	}
+
+	if nullable {
+		name += ", nullable"
+	}
+
+	return name
+}
+
+func (md *markdown) typeNames(schemas []*schema, separator string) string {
+	names := make([]string, len(schemas))
+
+	for idx, sc := range schemas {
+		names[idx] = md.typeName(sc)
+	}
+
+	return strings.Join(names, separator)
+}
+
+// description returns the description of a property with the allowed
+// values and the default value of the schema.
+func (md *markdown) description(sc *schema) string {
+	sc = md.resolve(sc)
+	if sc == nil {
+		return ""
+	}
+
+	var parts []string
+
+	if sc.Deprecated {
+		parts = append(parts, "Deprecated.")
+	}
+
+	parts = append(parts, strings.TrimSpace(sc.Description))
+
+	if len(sc.Enum) > 0 {
+		values := make([]string, len(sc.Enum))
+		for idx, value := range sc.Enum {
+			values[idx] = fmt.Sprintf("`%v`", value)
+		}
+
+		parts = append(parts, "One of: "+strings.Join(values, ", ")+".")
+	}
+
+	if sc.Const != nil {
+		parts = append(parts, fmt.Sprintf("Always `%v`.", sc.Const))
+	}
+
+	if sc.Default != nil {
+		parts = append(parts, fmt.Sprintf("Default: `%v`.", sc.Default))
+	}
+
+	return strings.TrimSpace(strings.Join(parts, " "))
+}
+
+// exampleValue returns an example of a value of the schema, the first
+// example of the schema if it has one. Objects keep the order of the
+// properties and references that are already being expanded are left
+// out, so that recursive schemas end.
+func (md *markdown) exampleValue(sc *schema, refs []string) interface{} {
+	if sc == nil || len(refs) > maxRefDepth {
+		return nil
+	}
+
+	if sc.Ref != "" {
+		for _, ref := range refs {
+			if ref == sc.file+"#"+sc.Ref {
+				return nil
+			}
+		}
+	}
+
+	sc, refs = md.resolveWithRefs(sc, refs)
+	if sc == nil {
+		return nil
+	}
+
+	switch {
+	case len(sc.Examples) > 0:
+		return normalizeYAML(sc.Examples[0])
+	case sc.Const != nil:
+		return normalizeYAML(sc.Const)
+	case sc.Default != nil:
+		return normalizeYAML(sc.Default)
+	case len(sc.Enum) > 0:
+		return normalizeYAML(sc.Enum[0])
+	case len(sc.OneOf) > 0:
+		return md.exampleValue(sc.OneOf[0], refs)
+	case len(sc.AnyOf) > 0:
+		return md.exampleValue(sc.AnyOf[0], refs)
+	}
+
+	switch exampleType(sc) {
+	case "array":
+		if item := md.exampleValue(sc.Items, refs); item != nil {
+			return []interface{}{item}
+		}
+
+		return []interface{}{}
+	case "string":
+		return stringExample(sc.Format)
+	case "integer":
+		return 0
+	case "number":
+		return 0.0
+	case "boolean":
+		return false
+	case "null":
+		return nil
+	}
+
+	var obj orderedObject
+
+	for _, prop := range md.objectProperties(sc, 0) {
+		if value := md.exampleValue(prop.schema, refs); value != nil {
+			obj = append(obj, orderedField{key: prop.name, value: value})
+		}
+	}
+
+	return obj
+}
+
+// exampleType returns the first type of the schema that isn't null, or
+// null if it's the only type.
+func exampleType(sc *schema) string {
+	for _, typ := range sc.Type {
+		if typ != "null" {
+			return typ
+		}
+	}
+
+	if len(sc.Type) > 0 {
+		return "null"
+	}
+
+	return ""
+}
+
+func stringExample(format string) string {
+	switch format {
+	case "date-time":
+		return "2021-01-01T00:00:00Z"
+	case "date":
+		return "2021-01-01"
+	case "time":
+		return "00:00:00Z"
+	case "uuid":
+		return "3fa85f64-5717-4562-b3fc-2c963f66afa6"
+	case "email":
+		return "user@example.com"
+	case "uri", "url":
+		return "https://example.com"
+	default:
+		return "string"
+	}
+}
+
+// orderedObject is a JSON object that is encoded with the fields in
+// order, unlike a map.
+type orderedObject []orderedField
+
+type orderedField struct {
+	key   string
+	value interface{}
+}
+
+func (obj orderedObject) MarshalJSON() ([]byte, error) {
+	var buffer bytes.Buffer
+
+	buffer.WriteString("{")
+
+	for idx, field := range obj {
+		if idx > 0 {
+			buffer.WriteString(",")
+		}
+
+		key, err := json.Marshal(field.key)
+		if err != nil {
+			return nil, err
+		}
+
+		value, err := json.Marshal(field.value)
+		if err != nil {
+			return nil, err
+		}
+
+		buffer.Write(key)
+		buffer.WriteString(":")
+		buffer.Write(value)
+	}
+
+	buffer.WriteString("}")
+
+	return buffer.Bytes(), nil
+}
+
+// normalizeYAML converts the maps decoded from YAML, which can have keys
+// of any type, to maps that can be encoded to JSON.
+func normalizeYAML(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[interface{}]interface{}:
+		object := make(map[string]interface{}, len(v))
+		for key, value := range v {
+			object[fmt.Sprint(key)] = normalizeYAML(value)
+		}
+
+		return object
+	case []interface{}:
+		array := make([]interface{}, len(v))
+		for idx, value := range v {
+			array[idx] = normalizeYAML(value)
+		}
+
+		return array
+	default:
+		return v
+	}
+}
+
+func yesNo(b bool) string {
+	if b {
+		return "yes"
+	}
+
+	return "no"
+}
@@ -0,0 +1,66 @@
+package gen_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	jsonschema_gen "github.com/lonnblad/go-service-doc/jsonschema-gen"
+)
+
+func Test_BuildMarkdown(t *testing.T) {
+	md, err := jsonschema_gen.BuildMarkdown("testdata", "testdata/events/bar-created.json")
+	require.NoError(t, err)
+
+	expected := []string{
+		"Published when a bar is created.\n\n",
+		"| `id` | string (uuid) | yes | The ID of the bar. |\n",
+		"| `location` | Location | yes |  |\n",
+		"| `tags` | []tag | no |  |\n",
+		"| `status` | string | no | One of: `open`, `closed`. Default: `open`. |\n",
+		"| `closed_at` | string (date-time), nullable | no | When the bar \\| closed. |\n",
+		"Properties of `location`:\n\n| Property | Type | Required | Description |\n" +
+			"| -------- | ---- | -------- | ----------- |\n" +
+			"| `city` | string | yes | The city of the bar. |\n" +
+			"| `country` | string | no | The country code of the bar. |\n",
+		"Properties of `tags[]`:\n\n| Property | Type | Required | Description |\n" +
+			"| -------- | ---- | -------- | ----------- |\n" +
+			"| `name` | string | no |  |\n" +
+			"| `parent` | tag | no |  |\n",
+		"Example payload:\n\n```json\n{\n" +
+			"  \"id\": \"3fa85f64-5717-4562-b3fc-2c963f66afa6\",\n" +
+			"  \"name\": \"Donkey Bar\",\n" +
+			"  \"location\": {\n    \"city\": \"string\",\n    \"country\": \"SE\"\n  },\n" +
+			"  \"tags\": [\n    {\n      \"name\": \"string\"\n    }\n  ],\n" +
+			"  \"status\": \"open\",\n" +
+			"  \"closed_at\": \"2021-01-01T00:00:00Z\"\n}\n```\n",
+	}
+
+	for _, str := range expected {
+		assert.Contains(t, string(md), str)
+	}
+
+	assert.NotContains(t, string(md), "Properties of `tags[].parent`")
+}
+
+func Test_BuildMarkdown_Errors(t *testing.T) {
+	testcases := []struct {
+		name     string
+		path     string
+		expected string
+	}{
+		{name: "missing file", path: "testdata/events/missing.json", expected: "no such file"},
+		{name: "outside of the root", path: "testdata/events/outside-ref.json", expected: "outside of the source directory"},
+		{name: "missing definition", path: "testdata/events/missing-ref.json", expected: "[#/$defs/bar] not found"},
+	}
+
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := jsonschema_gen.BuildMarkdown("testdata", tc.path)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tc.expected)
+		})
+	}
+}
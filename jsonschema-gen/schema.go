@@ -0,0 +1,285 @@
+package gen
+
+import (
+	"io/ioutil"
+	"net/url"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v2"
+)
+
+// schema is a JSON Schema, only the keywords that are documented are
+// decoded. The schemas of other documents and of the definitions are
+// referenced with $ref, which is resolved by the resolver.
+type schema struct {
+	Ref         string        `yaml:"$ref"`
+	Title       string        `yaml:"title"`
+	Description string        `yaml:"description"`
+	Type        schemaTypes   `yaml:"type"`
+	Format      string        `yaml:"format"`
+	Properties  properties    `yaml:"properties"`
+	Required    []string      `yaml:"required"`
+	Items       *schema       `yaml:"items"`
+	AllOf       []*schema     `yaml:"allOf"`
+	OneOf       []*schema     `yaml:"oneOf"`
+	AnyOf       []*schema     `yaml:"anyOf"`
+	Enum        []interface{} `yaml:"enum"`
+	Const       interface{}   `yaml:"const"`
+	Default     interface{}   `yaml:"default"`
+	Examples    []interface{} `yaml:"examples"`
+	Deprecated  bool          `yaml:"deprecated"`
+
+	// file is the path of the document of the schema, which references
+	// are relative to.
+	file string
+}
+
+// schemaTypes is the type of a schema, which is either a single type or
+// a list of types, i.e. ["string", "null"].
+type schemaTypes []string
+
+func (ts *schemaTypes) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var typ string
+	if err := unmarshal(&typ); err == nil {
+		*ts = schemaTypes{typ}
+		return nil
+	}
+
+	var types []string
+	if err := unmarshal(&types); err != nil {
+		return err
+	}
+
+	*ts = types
+
+	return nil
+}
+
+func (ts schemaTypes) is(typ string) bool {
+	for _, t := range ts {
+		if t == typ {
+			return true
+		}
+	}
+
+	return false
+}
+
+// properties are the properties of a schema, in the order they are
+// documented.
+type properties []property
+
+type property struct {
+	name   string
+	schema *schema
+}
+
+func (ps *properties) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var mapping yaml.MapSlice
+	if err := unmarshal(&mapping); err != nil {
+		return err
+	}
+
+	for _, item := range mapping {
+		key, ok := item.Key.(string)
+		if !ok {
+			return errors.Errorf("expected a string key, got [%v]", item.Key)
+		}
+
+		p := property{name: key, schema: &schema{}}
+		if err := decodeNode(item.Value, p.schema); err != nil {
+			return errors.Wrapf(err, "[%s]", key)
+		}
+
+		*ps = append(*ps, p)
+	}
+
+	return nil
+}
+
+// decodeNode decodes a node of a document, decoded as yaml.MapSlice to
+// keep the order of the keys, to a schema.
+func decodeNode(node interface{}, out *schema) error {
+	bs, err := yaml.Marshal(node)
+	if err != nil {
+		return errors.Wrap(err, "yaml.Marshal failed")
+	}
+
+	return yaml.Unmarshal(bs, out)
+}
+
+// setFile sets the file of the schema and of the schemas in it.
+func (sc *schema) setFile(file string) {
+	if sc == nil {
+		return
+	}
+
+	sc.file = file
+
+	for _, p := range sc.Properties {
+		p.schema.setFile(file)
+	}
+
+	sc.Items.setFile(file)
+
+	for _, subs := range [][]*schema{sc.AllOf, sc.OneOf, sc.AnyOf} {
+		for _, sub := range subs {
+			sub.setFile(file)
+		}
+	}
+}
+
+// resolver resolves references to schemas in the same document and in
+// other documents in the root directory, i.e. #/$defs/Location and
+// location.json#/definitions/Location.
+type resolver struct {
+	rootDir   string
+	documents map[string]interface{}
+}
+
+func newResolver(rootDir string) *resolver {
+	return &resolver{rootDir: rootDir, documents: map[string]interface{}{}}
+}
+
+// load returns the schema at the root of the document at path.
+func (r *resolver) load(path string) (_ *schema, err error) {
+	return r.lookup(filepath.Clean(path), "")
+}
+
+// resolve follows the references of a schema until it gets to a schema
+// without one, it returns the schema itself if it isn't a reference.
+func (r *resolver) resolve(sc *schema) (_ *schema, err error) {
+	for depth := 0; sc != nil && sc.Ref != ""; depth++ {
+		if depth > maxRefDepth {
+			err = errors.Errorf("too many nested references [%s]", sc.Ref)
+			return
+		}
+
+		if sc, err = r.resolveRef(sc.file, sc.Ref); err != nil {
+			return
+		}
+	}
+
+	return sc, nil
+}
+
+func (r *resolver) resolveRef(file, ref string) (_ *schema, err error) {
+	ptr := ""
+	if idx := strings.Index(ref, "#"); idx >= 0 {
+		ref, ptr = ref[:idx], ref[idx+1:]
+	}
+
+	if ptr, err = url.PathUnescape(ptr); err != nil {
+		err = errors.Wrapf(err, "invalid reference [%s]", ref)
+		return
+	}
+
+	if ref == "" {
+		return r.lookup(file, ptr)
+	}
+
+	if strings.Contains(ref, "://") {
+		err = errors.Errorf("only references to files in the source directory are supported, got [%s]", ref)
+		return
+	}
+
+	path := filepath.Join(filepath.Dir(file), filepath.FromSlash(ref))
+
+	rel, err := filepath.Rel(r.rootDir, path)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		err = errors.Errorf("the reference [%s] is outside of the source directory", ref)
+		return
+	}
+
+	return r.lookup(path, ptr)
+}
+
+// lookup returns the schema at the JSON pointer in the document at path,
+// i.e. /$defs/Location.
+func (r *resolver) lookup(path, ptr string) (_ *schema, err error) {
+	node, err := r.document(path)
+	if err != nil {
+		return
+	}
+
+	if ptr != "" {
+		for _, token := range strings.Split(strings.TrimPrefix(ptr, "/"), "/") {
+			token = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+
+			var found bool
+			if node, found = child(node, token); !found {
+				err = errors.Errorf("[#%s] not found in [%s]", ptr, path)
+				return
+			}
+		}
+	}
+
+	sc := &schema{}
+	if err = decodeNode(node, sc); err != nil {
+		err = errors.Wrapf(err, "decodeNode failed for [%s#%s]", path, ptr)
+		return
+	}
+
+	sc.setFile(path)
+
+	return sc, nil
+}
+
+func (r *resolver) document(path string) (_ interface{}, err error) {
+	if document, exists := r.documents[path]; exists {
+		return document, nil
+	}
+
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		err = errors.Wrap(err, "ioutil.ReadFile failed")
+		return
+	}
+
+	var document yaml.MapSlice
+	if err = yaml.Unmarshal(content, &document); err != nil {
+		err = errors.Wrapf(err, "yaml.Unmarshal failed for [%s]", path)
+		return
+	}
+
+	r.documents[path] = document
+
+	return document, nil
+}
+
+// child returns the value of a key of a mapping or of an index of a list.
+func child(node interface{}, token string) (interface{}, bool) {
+	switch n := node.(type) {
+	case yaml.MapSlice:
+		for _, item := range n {
+			if key, ok := item.Key.(string); ok && key == token {
+				return item.Value, true
+			}
+		}
+	case []interface{}:
+		if idx, err := strconv.Atoi(token); err == nil && idx >= 0 && idx < len(n) {
+			return n[idx], true
+		}
+	}
+
+	return nil, false
+}
+
+// refName returns the name of a referenced schema, i.e. Location for
+// #/$defs/Location or location for location.json.
+func refName(ref string) string {
+	if idx := strings.Index(ref, "#"); idx >= 0 && idx < len(ref)-1 {
+		return ref[strings.LastIndex(ref, "/")+1:]
+	}
+
+	name := filepath.Base(strings.TrimSuffix(ref, "#"))
+
+	return strings.TrimSuffix(name, filepath.Ext(name))
+}
+
+// maxRefDepth limits how deep references and nested schemas are
+// followed, which breaks cycles in recursive schemas.
+const maxRefDepth = 8
@@ -8,9 +8,11 @@ import (
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
 
+	"github.com/lonnblad/go-service-doc/config"
 	"github.com/lonnblad/go-service-doc/exporting/golang"
 	"github.com/lonnblad/go-service-doc/exporting/simple"
 	"github.com/lonnblad/go-service-doc/parser"
+	search_gen "github.com/lonnblad/go-service-doc/search-gen"
 )
 
 func init() {
@@ -32,22 +34,41 @@ func init() {
 }
 
 func main() {
+	configFile := flag.String("c", "", "YAML config file, i.e. go-service-doc.yaml.")
 	serviceFilename := flag.String("s", "service.md", "Main Markdown file for the service.")
 	sourceDir := flag.String("d", "docs", "Directory where to get markdown files.")
 	outputDir := flag.String("o", "docs", "Directory where to write output.")
 	basepath := flag.String("p", "/docs", "Base path for the generated documentation.")
+	optimizeImages := flag.Bool("i", false, "Optimize PNG and JPEG images and generate downscaled variants.")
+	dotCommand := flag.String("g", parser.DefaultDotCommand, "Command used to render dot diagrams to SVG.")
+	language := flag.String("l", search_gen.DefaultLanguage, "Language of the documentation, used for stemming in the search.")
 
 	flag.Parse()
 
+	cfg, err := config.Load(*configFile)
+	if err != nil {
+		zap.L().With(zap.Error(err)).
+			Error("failed to load the config file")
+
+		return
+	}
+
 	mdParser := parser.NewParser().
 		WithSourceDir(*sourceDir).
 		WithOutputDir(*outputDir).
 		WithBasepath(*basepath).
+		WithImageOptimization(*optimizeImages).
+		WithDiagramRenderer("dot", parser.CommandDiagramRenderer(*dotCommand)).
+		WithMarkdownConfig(cfg.Markdown).
+		WithGoPackages(cfg.GoPackages).
+		WithChangelog(cfg.Changelog).
+		WithBaseURL(cfg.BaseURL).
+		WithGitHistory(cfg.GitHistory).
 		ServiceFilename(*serviceFilename)
 
 	mdParser.Run()
 
-	if err := mdParser.Error(); err != nil {
+	if err = mdParser.Error(); err != nil {
 		zap.L().With(zap.Error(err)).
 			Error("parser returned an error")
 
@@ -56,17 +77,19 @@ func main() {
 
 	pages := mdParser.Pages()
 	staticFiles := mdParser.StaticFiles()
+	generatedFiles := mdParser.GeneratedFiles()
 	searchPage := mdParser.SearchPage()
 
 	simpleExporter := simple.NewExporter().
 		WithSourceDir(*sourceDir).
 		WithOutputDir(*outputDir).
 		WithPages(pages).
-		WithStaticFiles(staticFiles)
+		WithStaticFiles(staticFiles).
+		WithGeneratedFiles(generatedFiles)
 
 	simpleExporter.Run()
 
-	if err := simpleExporter.Error(); err != nil {
+	if err = simpleExporter.Error(); err != nil {
 		zap.L().With(zap.Error(err)).
 			Error("exporting simple returned an error")
 
@@ -76,13 +99,15 @@ func main() {
 	goExporter := golang.NewExporter().
 		WithOutputDir(*outputDir).
 		WithBasepath(*basepath).
+		WithLanguage(*language).
 		WithPages(pages).
 		WithStaticFiles(staticFiles).
+		WithGeneratedFiles(generatedFiles).
 		WithSearchPage(searchPage)
 
 	goExporter.Run()
 
-	if err := goExporter.Error(); err != nil {
+	if err = goExporter.Error(); err != nil {
 		zap.L().With(zap.Error(err)).
 			Error("exporting golang returned an error")
 
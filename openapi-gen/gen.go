@@ -0,0 +1,631 @@
+package gen
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/lonnblad/go-service-doc/utils"
+)
+
+// BuildMarkdown generates the Markdown of a documentation page from an
+// OpenAPI 3 or Swagger 2 document, with a section per endpoint and a
+// section with the schemas of the components or definitions.
+func BuildMarkdown(content []byte) (_ []byte, err error) {
+	s, err := parseSpec(content)
+	if err != nil {
+		err = errors.Wrap(err, "parseSpec failed")
+		return
+	}
+
+	if s.Info.Title == "" {
+		err = errors.New("the spec is missing info.title")
+		return
+	}
+
+	md := &markdown{spec: s}
+	md.writeInfo()
+
+	for _, path := range s.Paths {
+		for _, mo := range path.item.operations() {
+			md.writeOperation(path.path, mo.method, path.item, mo.operation)
+		}
+	}
+
+	md.writeSchemas()
+
+	return []byte(md.String()), nil
+}
+
+type markdown struct {
+	strings.Builder
+	spec spec
+}
+
+func (md *markdown) line(format string, args ...interface{}) {
+	fmt.Fprintf(md, format+"\n", args...)
+}
+
+func (md *markdown) writeInfo() {
+	md.line("# %s {#%s}\n", md.spec.Info.Title, utils.Slug(md.spec.Info.Title))
+
+	if md.spec.Info.Description != "" {
+		md.line("%s\n", strings.TrimSpace(md.spec.Info.Description))
+	}
+
+	if md.spec.Info.Version != "" {
+		md.line("Version: `%s`\n", md.spec.Info.Version)
+	}
+
+	if len(md.spec.Servers) == 0 {
+		return
+	}
+
+	md.line("| Server | Description |")
+	md.line("| ------ | ----------- |")
+
+	for _, srv := range md.spec.Servers {
+		md.line("| `%s` | %s |", srv.URL, utils.EscapeTableCell(srv.Description))
+	}
+
+	md.line("")
+}
+
+func (md *markdown) writeOperation(path, method string, item *pathItem, op *operation) {
+	id := operationID(path, method, op)
+
+	md.line("## %s %s {#%s}\n", method, path, id)
+
+	if op.Deprecated {
+		md.line("> [!WARNING]\n> This endpoint is deprecated.\n")
+	}
+
+	if op.Summary != "" {
+		md.line("%s\n", strings.TrimSpace(op.Summary))
+	}
+
+	if op.Description != "" {
+		md.line("%s\n", strings.TrimSpace(op.Description))
+	}
+
+	parameters := md.parameters(item, op)
+	if len(parameters) > 0 {
+		md.line("### Parameters {#%s-parameters}\n", id)
+		md.line("| Name | In | Type | Required | Description |")
+		md.line("| ---- | -- | ---- | -------- | ----------- |")
+
+		for _, p := range parameters {
+			md.line("| `%s` | %s | %s | %s | %s |",
+				p.Name, p.In, md.typeName(p.Schema), yesNo(p.Required), utils.EscapeTableCell(md.description(p.Description, p.Schema)))
+		}
+
+		md.line("")
+	}
+
+	body := md.spec.resolveRequestBody(op.RequestBody)
+	if body != nil {
+		md.line("### Request Body {#%s-request-body}\n", id)
+
+		if body.Description != "" {
+			md.line("%s\n", strings.TrimSpace(body.Description))
+		}
+
+		md.writeContent(body.Content)
+	}
+
+	md.writeExampleRequest(id, path, method, parameters, body)
+	md.writeResponses(id, op)
+}
+
+func (md *markdown) parameters(item *pathItem, op *operation) []*parameter {
+	var parameters []*parameter
+
+	seen := map[string]bool{}
+
+	for _, p := range append(append([]*parameter{}, op.Parameters...), item.Parameters...) {
+		p = md.spec.resolveParameter(p)
+		if p == nil || seen[p.In+":"+p.Name] {
+			continue
+		}
+
+		seen[p.In+":"+p.Name] = true
+		parameters = append(parameters, p)
+	}
+
+	sort.SliceStable(parameters, func(i, j int) bool {
+		return parameterOrder(parameters[i].In) < parameterOrder(parameters[j].In)
+	})
+
+	return parameters
+}
+
+func parameterOrder(in string) int {
+	switch in {
+	case "path":
+		return 0
+	case "query":
+		return 1
+	case "header":
+		return 2
+	default:
+		return 3
+	}
+}
+
+// writeContent writes the content types and the schema of the content.
+func (md *markdown) writeContent(content map[string]*mediaType) {
+	for _, contentType := range sortedKeys(content) {
+		media := content[contentType]
+		if media == nil || media.Schema == nil {
+			md.line("Content type: `%s`\n", contentType)
+			continue
+		}
+
+		md.line("Content type: `%s`, schema: %s\n", contentType, md.typeName(media.Schema))
+
+		if media.Schema.Ref == "" {
+			md.writeProperties(media.Schema)
+		}
+	}
+}
+
+// writeExampleRequest writes a curl command with the required parameters
+// and an example of the request body.
+func (md *markdown) writeExampleRequest(id, path, method string, parameters []*parameter, body *requestBody) {
+	var (
+		query []string
+		args  []string
+	)
+
+	for _, p := range parameters {
+		if !p.Required {
+			continue
+		}
+
+		value := fmt.Sprint(md.parameterExample(p))
+
+		switch p.In {
+		case "path":
+			path = strings.ReplaceAll(path, "{"+p.Name+"}", value)
+		case "query":
+			query = append(query, p.Name+"="+value)
+		case "header":
+			args = append(args, "-H "+shellQuote(p.Name+": "+value))
+		}
+	}
+
+	if len(query) > 0 {
+		path += "?" + strings.Join(query, "&")
+	}
+
+	if body != nil {
+		if contentType, example, ok := md.jsonExample(body.Content); ok {
+			args = append(args, "-H "+shellQuote("Content-Type: "+contentType), "-d "+shellQuote(example))
+		}
+	}
+
+	md.line("### Example Request {#%s-example-request}\n", id)
+	md.line("```sh")
+	md.line("%s", strings.Join(append([]string{"curl -X " + method + " " + shellQuote(md.serverURL()+path)}, args...), " \\\n  "))
+	md.line("```\n")
+}
+
+func (md *markdown) writeResponses(id string, op *operation) {
+	if len(op.Responses) == 0 {
+		return
+	}
+
+	md.line("### Responses {#%s-responses}\n", id)
+	md.line("| Status | Description | Schema |")
+	md.line("| ------ | ----------- | ------ |")
+
+	var exampleContent map[string]*mediaType
+
+	for _, status := range sortedKeys(op.Responses) {
+		r := md.spec.resolveResponse(op.Responses[status])
+		if r == nil {
+			continue
+		}
+
+		var schemas []string
+
+		for _, contentType := range sortedKeys(r.Content) {
+			if media := r.Content[contentType]; media != nil && media.Schema != nil {
+				schemas = append(schemas, md.typeName(media.Schema))
+			}
+		}
+
+		md.line("| `%s` | %s | %s |", status, utils.EscapeTableCell(r.Description), strings.Join(unique(schemas), ", "))
+
+		if exampleContent == nil && strings.HasPrefix(status, "2") {
+			exampleContent = r.Content
+		}
+	}
+
+	md.line("")
+
+	if _, example, ok := md.jsonExample(exampleContent); ok {
+		md.line("### Example Response {#%s-example-response}\n", id)
+		md.line("```json\n%s\n```\n", example)
+	}
+}
+
+func (md *markdown) writeSchemas() {
+	if len(md.spec.Components.Schemas) == 0 {
+		return
+	}
+
+	md.line("## Schemas {#schemas}\n")
+
+	for _, name := range sortedKeys(md.spec.Components.Schemas) {
+		sc := md.spec.Components.Schemas[name]
+
+		md.line("### %s {#%s}\n", name, schemaID(name))
+
+		if sc.Description != "" {
+			md.line("%s\n", strings.TrimSpace(sc.Description))
+		}
+
+		if len(md.objectProperties(sc, 0)) == 0 {
+			md.line("Type: %s\n", md.typeName(sc))
+			continue
+		}
+
+		md.writeProperties(sc)
+	}
+}
+
+// writeProperties writes a table with the properties of an object schema,
+// the properties of nested objects are named with a dot, i.e. owner.name.
+func (md *markdown) writeProperties(sc *schema) {
+	rows := md.propertyRows("", sc, 0)
+	if len(rows) == 0 {
+		return
+	}
+
+	md.line("| Field | Type | Required | Description |")
+	md.line("| ----- | ---- | -------- | ----------- |")
+
+	for _, row := range rows {
+		md.line("%s", row)
+	}
+
+	md.line("")
+}
+
+func (md *markdown) propertyRows(prefix string, sc *schema, depth int) (rows []string) {
+	sc = md.spec.resolveSchema(sc)
+	if sc == nil || depth > maxRefDepth {
+		return
+	}
+
+	required := map[string]bool{}
+	for _, name := range md.requiredProperties(sc) {
+		required[name] = true
+	}
+
+	for _, prop := range md.objectProperties(sc, 0) {
+		name := prefix + prop.name
+
+		rows = append(rows, fmt.Sprintf("| `%s` | %s | %s | %s |",
+			name, md.typeName(prop.schema), yesNo(required[prop.name]), utils.EscapeTableCell(md.description(prop.schema.Description, prop.schema))))
+
+		// Inline objects are documented in the same table, referenced
+		// schemas in their own section.
+		if prop.schema.Ref == "" && len(prop.schema.Properties) > 0 {
+			rows = append(rows, md.propertyRows(name+".", prop.schema, depth+1)...)
+		}
+	}
+
+	return rows
+}
+
+// objectProperties returns the properties of a schema, including the
+// properties of the schemas in allOf.
+func (md *markdown) objectProperties(sc *schema, depth int) (props properties) {
+	sc = md.spec.resolveSchema(sc)
+	if sc == nil || depth > maxRefDepth {
+		return
+	}
+
+	for _, sub := range sc.AllOf {
+		props = append(props, md.objectProperties(sub, depth+1)...)
+	}
+
+	return append(props, sc.Properties...)
+}
+
+func (md *markdown) requiredProperties(sc *schema) []string {
+	required := append([]string{}, sc.Required...)
+
+	for _, sub := range sc.AllOf {
+		if sub = md.spec.resolveSchema(sub); sub != nil {
+			required = append(required, sub.Required...)
+		}
+	}
+
+	return required
+}
+
+// typeName returns the type of a schema, references to schemas are
+// linked to their section.
+func (md *markdown) typeName(sc *schema) string {
+	if sc == nil {
+		return ""
+	}
+
+	if sc.Ref != "" {
+		name := refName(sc.Ref)
+		return fmt.Sprintf("[%s](#%s)", name, schemaID(name))
+	}
+
+	var name string
+
+	switch {
+	case len(sc.AllOf) > 0:
+		name = md.typeNames(sc.AllOf, " & ")
+	case len(sc.OneOf) > 0:
+		name = md.typeNames(sc.OneOf, " \\| ")
+	case len(sc.AnyOf) > 0:
+		name = md.typeNames(sc.AnyOf, " \\| ")
+	case sc.Type == "array":
+		name = "[]" + md.typeName(sc.Items)
+	case sc.Type == "":
+		name = "object"
+	default:
+		name = sc.Type
+	}
+
+	if sc.Format != "" {
+		name += " (" + sc.Format + ")"
+	}
+
+	if sc.Nullable {
+		name += ", nullable"
+	}
+
+	return name
+}
+
+func (md *markdown) typeNames(schemas []*schema, separator string) string {
+	names := make([]string, len(schemas))
+
+	for idx, sc := range schemas {
+		names[idx] = md.typeName(sc)
+	}
+
+	return strings.Join(names, separator)
+}
+
+// description returns the description of a parameter or a property with
+// the allowed values and the default value of the schema.
+func (md *markdown) description(description string, sc *schema) string {
+	parts := []string{strings.TrimSpace(description)}
+
+	if sc = md.spec.resolveSchema(sc); sc != nil {
+		if len(sc.Enum) > 0 {
+			values := make([]string, len(sc.Enum))
+			for idx, value := range sc.Enum {
+				values[idx] = fmt.Sprintf("`%v`", value)
+			}
+
+			parts = append(parts, "One of: "+strings.Join(values, ", ")+".")
+		}
+
+		if sc.Default != nil {
+			parts = append(parts, fmt.Sprintf("Default: `%v`.", sc.Default))
+		}
+	}
+
+	return strings.TrimSpace(strings.Join(parts, " "))
+}
+
+// serverURL returns the URL of the first server, which is the prefix of
+// the paths in the example requests.
+func (md *markdown) serverURL() string {
+	if len(md.spec.Servers) == 0 {
+		return ""
+	}
+
+	return strings.TrimSuffix(md.spec.Servers[0].URL, "/")
+}
+
+// jsonExample returns an indented JSON example of the first JSON content
+// type of the content.
+func (md *markdown) jsonExample(content map[string]*mediaType) (contentType, example string, ok bool) {
+	for _, ct := range sortedKeys(content) {
+		media := content[ct]
+		if media == nil || !strings.Contains(ct, "json") {
+			continue
+		}
+
+		value := media.Example
+		if value == nil {
+			value = md.exampleValue(media.Schema, 0)
+		}
+
+		if value == nil {
+			continue
+		}
+
+		bs, err := json.MarshalIndent(normalizeYAML(value), "", "  ")
+		if err != nil {
+			continue
+		}
+
+		return ct, string(bs), true
+	}
+
+	return
+}
+
+func (md *markdown) parameterExample(p *parameter) interface{} {
+	if p.Example != nil {
+		return p.Example
+	}
+
+	if value := md.exampleValue(p.Schema, 0); value != nil {
+		return value
+	}
+
+	return p.Name
+}
+
+// exampleValue returns an example of a value of the schema, the example
+// of the schema if it has one.
+func (md *markdown) exampleValue(sc *schema, depth int) interface{} {
+	sc = md.spec.resolveSchema(sc)
+	if sc == nil || depth > maxRefDepth {
+		return nil
+	}
+
+	switch {
+	case sc.Example != nil:
+		return sc.Example
+	case sc.Default != nil:
+		return sc.Default
+	case len(sc.Enum) > 0:
+		return sc.Enum[0]
+	case len(sc.OneOf) > 0:
+		return md.exampleValue(sc.OneOf[0], depth+1)
+	case len(sc.AnyOf) > 0:
+		return md.exampleValue(sc.AnyOf[0], depth+1)
+	}
+
+	switch sc.Type {
+	case "array":
+		if item := md.exampleValue(sc.Items, depth+1); item != nil {
+			return []interface{}{item}
+		}
+
+		return []interface{}{}
+	case "string":
+		return stringExample(sc.Format)
+	case "integer":
+		return 0
+	case "number":
+		return 0.0
+	case "boolean":
+		return false
+	}
+
+	object := map[string]interface{}{}
+
+	for _, prop := range md.objectProperties(sc, 0) {
+		if value := md.exampleValue(prop.schema, depth+1); value != nil {
+			object[prop.name] = value
+		}
+	}
+
+	return object
+}
+
+func stringExample(format string) string {
+	switch format {
+	case "date-time":
+		return "2021-01-01T00:00:00Z"
+	case "date":
+		return "2021-01-01"
+	case "uuid":
+		return "3fa85f64-5717-4562-b3fc-2c963f66afa6"
+	case "email":
+		return "user@example.com"
+	case "uri", "url":
+		return "https://example.com"
+	default:
+		return "string"
+	}
+}
+
+// normalizeYAML converts the maps decoded from YAML, which can have keys
+// of any type, to maps that can be encoded to JSON.
+func normalizeYAML(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[interface{}]interface{}:
+		object := make(map[string]interface{}, len(v))
+		for key, value := range v {
+			object[fmt.Sprint(key)] = normalizeYAML(value)
+		}
+
+		return object
+	case map[string]interface{}:
+		object := make(map[string]interface{}, len(v))
+		for key, value := range v {
+			object[key] = normalizeYAML(value)
+		}
+
+		return object
+	case []interface{}:
+		array := make([]interface{}, len(v))
+		for idx, value := range v {
+			array[idx] = normalizeYAML(value)
+		}
+
+		return array
+	default:
+		return v
+	}
+}
+
+func operationID(path, method string, op *operation) string {
+	if op.OperationID != "" {
+		return utils.Slug(utils.ConvertToKebabCase(op.OperationID))
+	}
+
+	return utils.Slug(method + " " + path)
+}
+
+func schemaID(name string) string {
+	return "schema-" + utils.Slug(utils.ConvertToKebabCase(name))
+}
+
+func shellQuote(str string) string {
+	return "'" + strings.ReplaceAll(str, "'", `'\''`) + "'"
+}
+
+func yesNo(b bool) string {
+	if b {
+		return "yes"
+	}
+
+	return "no"
+}
+
+func unique(strs []string) (output []string) {
+	seen := map[string]bool{}
+
+	for _, str := range strs {
+		if !seen[str] {
+			seen[str] = true
+			output = append(output, str)
+		}
+	}
+
+	return
+}
+
+func sortedKeys(m interface{}) []string {
+	var keys []string
+
+	switch v := m.(type) {
+	case map[string]*mediaType:
+		for key := range v {
+			keys = append(keys, key)
+		}
+	case map[string]*response:
+		for key := range v {
+			keys = append(keys, key)
+		}
+	case map[string]*schema:
+		for key := range v {
+			keys = append(keys, key)
+		}
+	}
+
+	sort.Strings(keys)
+
+	return keys
+}
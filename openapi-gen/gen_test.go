@@ -0,0 +1,126 @@
+package gen_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	openapi_gen "github.com/lonnblad/go-service-doc/openapi-gen"
+)
+
+const openAPISpec = `
+openapi: 3.0.0
+info:
+  title: Bars API
+  version: 1.0.0
+servers:
+  - url: https://api.example.com/v1
+paths:
+  /bars/{id}:
+    get:
+      operationId: getBar
+      parameters:
+        - name: id
+          in: path
+          required: true
+          schema:
+            type: integer
+            example: 7
+      responses:
+        "200":
+          description: The bar.
+          content:
+            application/json:
+              schema:
+                $ref: "#/components/schemas/Bar"
+components:
+  schemas:
+    Bar:
+      type: object
+      required: [name]
+      properties:
+        name:
+          type: string
+          example: Monkey Bar
+`
+
+const swaggerSpec = `{
+	"swagger": "2.0",
+	"info": {"title": "Bars API", "version": "1.0.0"},
+	"host": "api.example.com",
+	"basePath": "/v1",
+	"paths": {
+		"/bars": {
+			"post": {
+				"parameters": [
+					{"name": "bar", "in": "body", "required": true, "schema": {"$ref": "#/definitions/Bar"}},
+					{"name": "dry_run", "in": "query", "type": "boolean"}
+				],
+				"responses": {"201": {"description": "Created.", "schema": {"$ref": "#/definitions/Bar"}}}
+			}
+		}
+	},
+	"definitions": {
+		"Bar": {"type": "object", "properties": {"name": {"type": "string", "example": "Monkey Bar"}}}
+	}
+}`
+
+func Test_IsSpec(t *testing.T) {
+	assert.True(t, openapi_gen.IsSpec([]byte(openAPISpec)))
+	assert.True(t, openapi_gen.IsSpec([]byte(swaggerSpec)))
+	assert.False(t, openapi_gen.IsSpec([]byte("tags: [bars]\n")))
+	assert.False(t, openapi_gen.IsSpec([]byte("not: [valid")))
+}
+
+func Test_BuildMarkdown(t *testing.T) {
+	testcases := []struct {
+		name     string
+		spec     string
+		expected []string
+	}{
+		{
+			name: "openapi 3",
+			spec: openAPISpec,
+			expected: []string{
+				"# Bars API {#bars-api}",
+				"## GET /bars/{id} {#get-bar}",
+				"| `id` | path | integer | yes |  |",
+				"curl -X GET 'https://api.example.com/v1/bars/7'",
+				"| `200` | The bar. | [Bar](#schema-bar) |",
+				"### Bar {#schema-bar}",
+				"| `name` | string | yes |  |",
+				"\"name\": \"Monkey Bar\"",
+			},
+		},
+		{
+			name: "swagger 2",
+			spec: swaggerSpec,
+			expected: []string{
+				"| `https://api.example.com/v1` |  |",
+				"## POST /bars {#post-bars}",
+				"| `dry_run` | query | boolean | no |  |",
+				"Content type: `application/json`, schema: [Bar](#schema-bar)",
+				"-d '{\n  \"name\": \"Monkey Bar\"\n}'",
+				"| `201` | Created. | [Bar](#schema-bar) |",
+			},
+		},
+	}
+
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			md, err := openapi_gen.BuildMarkdown([]byte(tc.spec))
+			require.NoError(t, err)
+
+			for _, expected := range tc.expected {
+				assert.Contains(t, string(md), expected)
+			}
+		})
+	}
+}
+
+func Test_BuildMarkdown_MissingTitle(t *testing.T) {
+	_, err := openapi_gen.BuildMarkdown([]byte("openapi: 3.0.0\n"))
+	assert.Error(t, err)
+}
@@ -0,0 +1,390 @@
+package gen
+
+import (
+	"strings"
+
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v2"
+)
+
+// spec is an OpenAPI 3 or Swagger 2 document, Swagger 2 documents are
+// converted to the OpenAPI 3 layout by normalize.
+type spec struct {
+	OpenAPI    string     `yaml:"openapi"`
+	Swagger    string     `yaml:"swagger"`
+	Info       info       `yaml:"info"`
+	Servers    []server   `yaml:"servers"`
+	Paths      paths      `yaml:"paths"`
+	Components components `yaml:"components"`
+
+	// Swagger 2
+	Host        string                `yaml:"host"`
+	BasePath    string                `yaml:"basePath"`
+	Schemes     []string              `yaml:"schemes"`
+	Consumes    []string              `yaml:"consumes"`
+	Produces    []string              `yaml:"produces"`
+	Definitions map[string]*schema    `yaml:"definitions"`
+	Parameters  map[string]*parameter `yaml:"parameters"`
+	Responses   map[string]*response  `yaml:"responses"`
+}
+
+type info struct {
+	Title       string `yaml:"title"`
+	Description string `yaml:"description"`
+	Version     string `yaml:"version"`
+}
+
+type server struct {
+	URL         string `yaml:"url"`
+	Description string `yaml:"description"`
+}
+
+type components struct {
+	Schemas       map[string]*schema      `yaml:"schemas"`
+	Parameters    map[string]*parameter   `yaml:"parameters"`
+	RequestBodies map[string]*requestBody `yaml:"requestBodies"`
+	Responses     map[string]*response    `yaml:"responses"`
+}
+
+type pathItem struct {
+	Parameters []*parameter `yaml:"parameters"`
+	Get        *operation   `yaml:"get"`
+	Put        *operation   `yaml:"put"`
+	Post       *operation   `yaml:"post"`
+	Delete     *operation   `yaml:"delete"`
+	Options    *operation   `yaml:"options"`
+	Head       *operation   `yaml:"head"`
+	Patch      *operation   `yaml:"patch"`
+}
+
+// operations returns the operations of the path by method, in the order
+// they are documented.
+func (pi pathItem) operations() []methodOperation {
+	var operations []methodOperation
+
+	for _, op := range []methodOperation{
+		{"GET", pi.Get}, {"POST", pi.Post}, {"PUT", pi.Put}, {"PATCH", pi.Patch},
+		{"DELETE", pi.Delete}, {"HEAD", pi.Head}, {"OPTIONS", pi.Options},
+	} {
+		if op.operation != nil {
+			operations = append(operations, op)
+		}
+	}
+
+	return operations
+}
+
+type methodOperation struct {
+	method    string
+	operation *operation
+}
+
+type operation struct {
+	OperationID string               `yaml:"operationId"`
+	Summary     string               `yaml:"summary"`
+	Description string               `yaml:"description"`
+	Deprecated  bool                 `yaml:"deprecated"`
+	Parameters  []*parameter         `yaml:"parameters"`
+	RequestBody *requestBody         `yaml:"requestBody"`
+	Responses   map[string]*response `yaml:"responses"`
+
+	// Swagger 2
+	Consumes []string `yaml:"consumes"`
+	Produces []string `yaml:"produces"`
+}
+
+type parameter struct {
+	Ref         string      `yaml:"$ref"`
+	Name        string      `yaml:"name"`
+	In          string      `yaml:"in"`
+	Description string      `yaml:"description"`
+	Required    bool        `yaml:"required"`
+	Schema      *schema     `yaml:"schema"`
+	Example     interface{} `yaml:"example"`
+
+	// Swagger 2
+	Type   string  `yaml:"type"`
+	Format string  `yaml:"format"`
+	Items  *schema `yaml:"items"`
+}
+
+type requestBody struct {
+	Ref         string                `yaml:"$ref"`
+	Description string                `yaml:"description"`
+	Required    bool                  `yaml:"required"`
+	Content     map[string]*mediaType `yaml:"content"`
+}
+
+type response struct {
+	Ref         string                `yaml:"$ref"`
+	Description string                `yaml:"description"`
+	Content     map[string]*mediaType `yaml:"content"`
+
+	// Swagger 2
+	Schema *schema `yaml:"schema"`
+}
+
+type mediaType struct {
+	Schema  *schema     `yaml:"schema"`
+	Example interface{} `yaml:"example"`
+}
+
+type schema struct {
+	Ref         string        `yaml:"$ref"`
+	Type        string        `yaml:"type"`
+	Format      string        `yaml:"format"`
+	Description string        `yaml:"description"`
+	Properties  properties    `yaml:"properties"`
+	Required    []string      `yaml:"required"`
+	Items       *schema       `yaml:"items"`
+	Enum        []interface{} `yaml:"enum"`
+	Example     interface{}   `yaml:"example"`
+	Default     interface{}   `yaml:"default"`
+	Nullable    bool          `yaml:"nullable"`
+	AllOf       []*schema     `yaml:"allOf"`
+	OneOf       []*schema     `yaml:"oneOf"`
+	AnyOf       []*schema     `yaml:"anyOf"`
+}
+
+// paths are the paths of a spec, in the order they are documented.
+type paths []path
+
+type path struct {
+	path string
+	item *pathItem
+}
+
+func (ps *paths) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	return unmarshalOrdered(unmarshal, func(key string, value func(interface{}) error) error {
+		p := path{path: key, item: &pathItem{}}
+		*ps = append(*ps, p)
+
+		return value(p.item)
+	})
+}
+
+// properties are the properties of a schema, in the order they are
+// documented.
+type properties []property
+
+type property struct {
+	name   string
+	schema *schema
+}
+
+func (ps *properties) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	return unmarshalOrdered(unmarshal, func(key string, value func(interface{}) error) error {
+		p := property{name: key, schema: &schema{}}
+		*ps = append(*ps, p)
+
+		return value(p.schema)
+	})
+}
+
+// unmarshalOrdered calls add for each key of a mapping in the order of
+// the document, the value function unmarshals the value of the key.
+func unmarshalOrdered(unmarshal func(interface{}) error, add func(key string, value func(interface{}) error) error) error {
+	var mapping yaml.MapSlice
+	if err := unmarshal(&mapping); err != nil {
+		return err
+	}
+
+	for _, item := range mapping {
+		key, ok := item.Key.(string)
+		if !ok {
+			return errors.Errorf("expected a string key, got [%v]", item.Key)
+		}
+
+		value := func(out interface{}) error {
+			bs, err := yaml.Marshal(item.Value)
+			if err != nil {
+				return errors.Wrap(err, "yaml.Marshal failed")
+			}
+
+			return yaml.Unmarshal(bs, out)
+		}
+
+		if err := add(key, value); err != nil {
+			return errors.Wrapf(err, "[%s]", key)
+		}
+	}
+
+	return nil
+}
+
+// IsSpec returns true if the content is an OpenAPI 3 or a Swagger 2
+// document, in YAML or JSON.
+func IsSpec(content []byte) bool {
+	var version struct {
+		OpenAPI string `yaml:"openapi"`
+		Swagger string `yaml:"swagger"`
+	}
+
+	if err := yaml.Unmarshal(content, &version); err != nil {
+		return false
+	}
+
+	return strings.HasPrefix(version.OpenAPI, "3.") || strings.HasPrefix(version.Swagger, "2.")
+}
+
+func parseSpec(content []byte) (s spec, err error) {
+	if err = yaml.Unmarshal(content, &s); err != nil {
+		err = errors.Wrap(err, "yaml.Unmarshal failed")
+		return
+	}
+
+	switch {
+	case strings.HasPrefix(s.OpenAPI, "3."):
+	case strings.HasPrefix(s.Swagger, "2."):
+		s.normalize()
+	default:
+		err = errors.New("expected an OpenAPI 3 or Swagger 2 document")
+		return
+	}
+
+	return s, nil
+}
+
+// normalize converts a Swagger 2 document to the OpenAPI 3 layout, i.e.
+// the body parameters to request bodies and the definitions to schemas.
+func (s *spec) normalize() {
+	if s.Host != "" {
+		scheme := "https"
+		if len(s.Schemes) > 0 {
+			scheme = s.Schemes[0]
+		}
+
+		s.Servers = []server{{URL: scheme + "://" + s.Host + s.BasePath}}
+	} else if s.BasePath != "" {
+		s.Servers = []server{{URL: s.BasePath}}
+	}
+
+	s.Components.Schemas = s.Definitions
+	s.Components.Parameters = s.Parameters
+	s.Components.Responses = s.Responses
+
+	for _, p := range s.Components.Parameters {
+		normalizeParameter(p)
+	}
+
+	for _, r := range s.Components.Responses {
+		normalizeResponse(r, s.Produces)
+	}
+
+	for _, path := range s.Paths {
+		for _, p := range path.item.Parameters {
+			normalizeParameter(p)
+		}
+
+		for _, mo := range path.item.operations() {
+			s.normalizeOperation(mo.operation)
+		}
+	}
+}
+
+func (s *spec) normalizeOperation(op *operation) {
+	consumes, produces := s.Consumes, s.Produces
+
+	if len(op.Consumes) > 0 {
+		consumes = op.Consumes
+	}
+
+	if len(op.Produces) > 0 {
+		produces = op.Produces
+	}
+
+	var parameters []*parameter
+
+	for _, p := range op.Parameters {
+		if p.In != "body" {
+			normalizeParameter(p)
+			parameters = append(parameters, p)
+
+			continue
+		}
+
+		op.RequestBody = &requestBody{
+			Description: p.Description,
+			Required:    p.Required,
+			Content:     map[string]*mediaType{},
+		}
+
+		for _, contentType := range defaultContentTypes(consumes) {
+			op.RequestBody.Content[contentType] = &mediaType{Schema: p.Schema}
+		}
+	}
+
+	op.Parameters = parameters
+
+	for _, r := range op.Responses {
+		normalizeResponse(r, produces)
+	}
+}
+
+func normalizeParameter(p *parameter) {
+	if p.Schema == nil && p.Type != "" {
+		p.Schema = &schema{Type: p.Type, Format: p.Format, Items: p.Items}
+	}
+}
+
+func normalizeResponse(r *response, produces []string) {
+	if r.Schema == nil {
+		return
+	}
+
+	r.Content = map[string]*mediaType{}
+
+	for _, contentType := range defaultContentTypes(produces) {
+		r.Content[contentType] = &mediaType{Schema: r.Schema}
+	}
+}
+
+func defaultContentTypes(contentTypes []string) []string {
+	if len(contentTypes) == 0 {
+		return []string{"application/json"}
+	}
+
+	return contentTypes
+}
+
+// refName returns the name of the component of a local reference, i.e.
+// Bar for #/components/schemas/Bar or #/definitions/Bar.
+func refName(ref string) string {
+	return ref[strings.LastIndex(ref, "/")+1:]
+}
+
+func (s spec) resolveSchema(sc *schema) *schema {
+	for depth := 0; sc != nil && sc.Ref != "" && depth < maxRefDepth; depth++ {
+		sc = s.Components.Schemas[refName(sc.Ref)]
+	}
+
+	return sc
+}
+
+func (s spec) resolveParameter(p *parameter) *parameter {
+	if p != nil && p.Ref != "" {
+		return s.Components.Parameters[refName(p.Ref)]
+	}
+
+	return p
+}
+
+func (s spec) resolveRequestBody(rb *requestBody) *requestBody {
+	if rb != nil && rb.Ref != "" {
+		return s.Components.RequestBodies[refName(rb.Ref)]
+	}
+
+	return rb
+}
+
+func (s spec) resolveResponse(r *response) *response {
+	if r != nil && r.Ref != "" {
+		return s.Components.Responses[refName(r.Ref)]
+	}
+
+	return r
+}
+
+// maxRefDepth limits how deep references and nested schemas are
+// followed, which breaks cycles in recursive schemas.
+const maxRefDepth = 8
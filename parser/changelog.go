@@ -0,0 +1,138 @@
+package parser
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+
+	changelog_gen "github.com/lonnblad/go-service-doc/changelog-gen"
+	"github.com/lonnblad/go-service-doc/core"
+)
+
+var nextSectionRegexp = regexp.MustCompile(`<h[12][ >]`)
+
+// findChangelog adds a page for the configured CHANGELOG.md, a
+// CHANGELOG.md in the source directory is found with the other Markdown
+// files.
+func (p *Parser) findChangelog() {
+	if p.changelog.Path == "" {
+		return
+	}
+
+	zap.L().Info("adding changelog")
+
+	path, err := filepath.Abs(p.changelog.Path)
+	if err != nil {
+		p.err = errors.Wrap(err, "filepath.Abs failed")
+		return
+	}
+
+	if !changelog_gen.IsChangelog(path) {
+		p.err = errors.Errorf("expected the changelog to be a %s, got [%s]", changelog_gen.Filename, p.changelog.Path)
+		return
+	}
+
+	if err = p.addGeneratedPage(path); err != nil {
+		p.err = err
+		return
+	}
+}
+
+// buildChangelogFeed builds the Atom feed of the releases of the
+// changelog, if it's enabled. The entries contain the HTML of the
+// sections of the releases.
+func (p *Parser) buildChangelogFeed() {
+	if !p.changelog.Feed {
+		return
+	}
+
+	zap.L().Info("building changelog feed")
+
+	if p.baseURL == "" {
+		p.err = errors.New("the changelog feed requires the base URL of the documentation")
+		return
+	}
+
+	var page *core.Page
+
+	for idx := range p.pages {
+		if changelog_gen.IsChangelog(p.pages[idx].Filepath) {
+			page = &p.pages[idx]
+			break
+		}
+	}
+
+	if page == nil {
+		p.err = errors.Errorf("the changelog feed is enabled, but no %s was found", changelog_gen.Filename)
+		return
+	}
+
+	content, err := ioutil.ReadFile(page.Filepath)
+	if err != nil {
+		p.err = errors.Wrap(err, "ioutil.ReadFile failed")
+		return
+	}
+
+	title := "Changelog"
+	if len(page.Headers) > 0 {
+		title = page.Headers[0].Title
+	}
+
+	if p.serviceTitle != "" {
+		title = p.serviceTitle + " " + title
+	}
+
+	baseURL := strings.TrimSuffix(p.baseURL, "/")
+	href := p.basepath + "/" + changelog_gen.FeedFilename
+
+	feed, err := changelog_gen.BuildFeed(changelog_gen.Feed{
+		Title:    title,
+		Link:     baseURL + page.WebPath,
+		SelfLink: baseURL + href,
+		Releases: changelog_gen.Releases(content),
+		Content: func(release changelog_gen.Release) string {
+			return sectionHTML(page.Markdown, release.ID)
+		},
+	})
+	if err != nil {
+		p.err = errors.Wrapf(err, "changelog_gen.BuildFeed failed for [%s]", page.Filepath)
+		return
+	}
+
+	p.feedTitle = title
+	p.feedHref = href
+	p.generatedFiles = append(p.generatedFiles, core.File{
+		Name:        "changelogFeed",
+		Href:        href,
+		Path:        changelog_gen.FeedFilename,
+		ContentType: changelog_gen.FeedContentType,
+		Content:     feed,
+	})
+}
+
+// sectionHTML returns the HTML after the heading with the ID up to the
+// next heading of level 1 or 2.
+func sectionHTML(html, id string) string {
+	start := strings.Index(html, ` id="`+id+`"`)
+	if start < 0 {
+		return ""
+	}
+
+	end := strings.Index(html[start:], "</h")
+	if end < 0 {
+		return ""
+	}
+
+	section := html[start+end:]
+	section = section[strings.Index(section, ">")+1:]
+
+	if loc := nextSectionRegexp.FindStringIndex(section); loc != nil {
+		section = section[:loc[0]]
+	}
+
+	return strings.TrimSpace(section)
+}
@@ -0,0 +1,81 @@
+package parser
+
+import (
+	"bytes"
+	"fmt"
+	"os/exec"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/lonnblad/go-service-doc/core"
+	"github.com/lonnblad/go-service-doc/utils"
+)
+
+// DefaultDotCommand is the command used to render dot diagrams to SVG.
+const DefaultDotCommand = "dot -Tsvg"
+
+// DiagramRenderer renders the source of a diagram to SVG.
+type DiagramRenderer func(source []byte) (svg []byte, err error)
+
+// CommandDiagramRenderer returns a DiagramRenderer that runs a local
+// command, i.e. dot -Tsvg, with the diagram source on stdin and reads
+// the SVG from stdout.
+func CommandDiagramRenderer(command string) DiagramRenderer {
+	return func(source []byte) (_ []byte, err error) {
+		args := strings.Fields(command)
+		if len(args) == 0 {
+			err = errors.New("the diagram command is empty")
+			return
+		}
+
+		var stdout, stderr bytes.Buffer
+
+		cmd := exec.Command(args[0], args[1:]...) // nolint: gosec
+		cmd.Stdin = bytes.NewReader(source)
+		cmd.Stdout = &stdout
+		cmd.Stderr = &stderr
+
+		if err = cmd.Run(); err != nil {
+			if output := strings.TrimSpace(stderr.String()); output != "" {
+				err = errors.Wrap(err, output)
+			}
+
+			err = errors.Wrapf(err, "[%s] failed", command)
+
+			return
+		}
+
+		return stdout.Bytes(), nil
+	}
+}
+
+// diagramRenderer returns the renderDiagram hook of a page, the SVG files
+// of the rendered diagrams are added to the static files as
+// <base_path>/static/diagrams/<page>-<n>.svg.
+func (p *Parser) diagramRenderer(page core.Page) func(language string, source []byte) (string, bool, error) {
+	var diagrams int
+
+	return func(language string, source []byte) (_ string, ok bool, err error) {
+		renderer, exists := p.diagramRenderers[language]
+		if !exists {
+			return "", false, nil
+		}
+
+		diagrams++
+
+		svg, err := renderer(source)
+		if err != nil {
+			err = errors.Wrapf(err, "failed to render %s diagram %d", language, diagrams)
+			return
+		}
+
+		hrefPath := fmt.Sprintf("diagrams/%s-%d.svg", utils.ConvertToKebabCase(page.Name), diagrams)
+		file, _, err := p.addStaticFileContent(hrefPath, svg)
+		if err != nil {
+			return
+		}
+
+		return file.Href, true, nil
+	}
+}
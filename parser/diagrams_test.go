@@ -0,0 +1,114 @@
+package parser_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lonnblad/go-service-doc/parser"
+)
+
+func Test_CommandDiagramRenderer(t *testing.T) {
+	testcases := []struct {
+		name     string
+		command  string
+		source   string
+		expected string
+		err      bool
+	}{
+		{name: "output", command: "cat", source: "<svg></svg>", expected: "<svg></svg>"},
+		{name: "arguments", command: "tr a-z A-Z", source: "svg", expected: "SVG"},
+		{name: "failing command", command: "false", source: "digraph {}", err: true},
+		{name: "missing command", command: "go-service-doc-missing-command", source: "digraph {}", err: true},
+		{name: "empty command", command: " ", source: "digraph {}", err: true},
+	}
+
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			svg, err := parser.CommandDiagramRenderer(tc.command)([]byte(tc.source))
+			if tc.err {
+				require.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, string(svg))
+		})
+	}
+}
+
+func Test_Parser_Diagrams(t *testing.T) {
+	files := map[string]string{
+		"page.md":       "# Bars {#bars}\n\n```dot\ndigraph { a -> b }\n```\n\n```go\nvar a = b\n```\n\n```dot\ndigraph { b -> c }\n```\n",
+		"donkey-bar.md": "# Donkey Bar {#donkey}\n\n```dot\ndigraph { c -> d }\n```\n",
+	}
+
+	testcases := []struct {
+		name     string
+		renderer parser.DiagramRenderer
+		expected map[string]string
+		err      string
+	}{
+		{
+			name: "rendered",
+			renderer: func(source []byte) ([]byte, error) {
+				return append([]byte("<svg>"), source...), nil
+			},
+			expected: map[string]string{
+				"/docs/static/diagrams/page-1.svg":       "<svg>digraph { a -> b }\n",
+				"/docs/static/diagrams/page-2.svg":       "<svg>digraph { b -> c }\n",
+				"/docs/static/diagrams/donkey-bar-1.svg": "<svg>digraph { c -> d }\n",
+			},
+		},
+		{
+			name: "failing renderer",
+			renderer: func(source []byte) ([]byte, error) {
+				return nil, errors.New("syntax error in line 1")
+			},
+			err: "failed to render dot diagram 1: syntax error in line 1",
+		},
+	}
+
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			mdParser := parseFiles(t, files, func(mdParser *parser.Parser) {
+				mdParser.WithDiagramRenderer("dot", tc.renderer)
+			})
+
+			if tc.err != "" {
+				require.Error(t, mdParser.Error())
+				assert.Contains(t, mdParser.Error().Error(), tc.err)
+
+				return
+			}
+
+			require.NoError(t, mdParser.Error())
+
+			pages := map[string]string{}
+			for _, page := range mdParser.Pages() {
+				pages[page.Name] = page.Markdown
+			}
+
+			diagrams := map[string]string{}
+
+			for _, file := range mdParser.StaticFiles() {
+				diagrams[file.Href] = string(file.Content)
+
+				page := pages["page"]
+				if strings.HasPrefix(file.Href, "/docs/static/diagrams/donkey-bar-") {
+					page = pages["donkeyBar"]
+				}
+
+				assert.Contains(t, page, `<img src="`+file.FingerprintedHref()+`"`)
+			}
+
+			assert.Equal(t, tc.expected, diagrams)
+			assert.NotContains(t, pages["page"], "a -&gt; b")
+		})
+	}
+}
@@ -0,0 +1,164 @@
+package parser
+
+import (
+	"bytes"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// directiveRegexp matches a directive on a line of its own, i.e.
+// {{< include "_partials/auth.md" >}}.
+var directiveRegexp = regexp.MustCompile(`^\s*\{\{<\s*([a-z_]+)(.*?)\s*>\}\}\s*$`)
+
+var directiveArgRegexp = regexp.MustCompile(`^\s*("(?:[^"\\]|\\.)*"|[^\s"]+)`)
+
+// directive is a directive found in a Markdown file.
+type directive struct {
+	name string
+	args []string
+	// filepath and line are where the directive is found.
+	filepath string
+	line     int
+	// includes are the files that are currently being included, used to
+	// detect include cycles.
+	includes []string
+}
+
+// directiveHandler returns the Markdown that replaces the directive.
+type directiveHandler func(d directive) ([]byte, error)
+
+func (p *Parser) directiveHandlers() map[string]directiveHandler {
+	return map[string]directiveHandler{
+		"include": p.include,
+		"snippet": p.snippet,
+		"config":  p.envConfig,
+		"schema":  p.jsonSchema,
+	}
+}
+
+// expandDirectives replaces the directives in the content with the
+// Markdown returned by their handlers. Directives in fenced code blocks
+// are left as is, so that they can be documented.
+func (p *Parser) expandDirectives(path string, content []byte, includes []string) (_ []byte, err error) {
+	handlers := p.directiveHandlers()
+
+	var (
+		output bytes.Buffer
+		fence  string
+	)
+
+	for idx, line := range strings.SplitAfter(string(content), "\n") {
+		if fence != "" {
+			if isClosingCodeFence(line, fence) {
+				fence = ""
+			}
+
+			output.WriteString(line)
+
+			continue
+		}
+
+		if fence = openingCodeFence(line); fence != "" {
+			output.WriteString(line)
+			continue
+		}
+
+		d, isDirective, parseErr := parseDirective(line)
+		if parseErr != nil {
+			err = errors.Wrapf(parseErr, "%s:%d", path, idx+1)
+			return
+		}
+
+		if !isDirective {
+			output.WriteString(line)
+			continue
+		}
+
+		handler, exists := handlers[d.name]
+		if !exists {
+			err = errors.Errorf("%s:%d: unknown directive [%s]", path, idx+1, d.name)
+			return
+		}
+
+		d.filepath = path
+		d.line = idx + 1
+		d.includes = includes
+
+		expanded, handlerErr := handler(d)
+		if handlerErr != nil {
+			err = errors.Wrapf(handlerErr, "%s:%d: %s directive failed", path, idx+1, d.name)
+			return
+		}
+
+		output.Write(expanded)
+
+		if len(expanded) > 0 && expanded[len(expanded)-1] != '\n' {
+			output.WriteString("\n")
+		}
+	}
+
+	return output.Bytes(), nil
+}
+
+// openingCodeFence returns the fence if the line opens a fenced code
+// block, i.e. ``` or ~~~.
+func openingCodeFence(line string) string {
+	trimmed := strings.TrimSpace(line)
+
+	for _, char := range []string{"`", "~"} {
+		if strings.HasPrefix(trimmed, strings.Repeat(char, 3)) {
+			return trimmed[:len(trimmed)-len(strings.TrimLeft(trimmed, char))]
+		}
+	}
+
+	return ""
+}
+
+func isClosingCodeFence(line, fence string) bool {
+	trimmed := strings.TrimSpace(line)
+	return len(trimmed) >= len(fence) && strings.Trim(trimmed, fence[:1]) == ""
+}
+
+func parseDirective(line string) (d directive, isDirective bool, err error) {
+	match := directiveRegexp.FindStringSubmatch(line)
+	if match == nil {
+		return
+	}
+
+	d.name = match[1]
+
+	for rest := match[2]; strings.TrimSpace(rest) != ""; {
+		argMatch := directiveArgRegexp.FindStringSubmatch(rest)
+		if argMatch == nil {
+			err = errors.Errorf("invalid directive arguments [%s]", strings.TrimSpace(match[2]))
+			return
+		}
+
+		arg := argMatch[1]
+		if strings.HasPrefix(arg, `"`) {
+			if arg, err = strconv.Unquote(arg); err != nil {
+				err = errors.Wrapf(err, "invalid directive argument [%s]", argMatch[1])
+				return
+			}
+		}
+
+		d.args = append(d.args, arg)
+		rest = rest[len(argMatch[0]):]
+	}
+
+	return d, true, nil
+}
+
+// resolvePath resolves a path of a directive relative to the source
+// directory.
+func (p *Parser) resolvePath(path string) string {
+	if filepath.IsAbs(path) {
+		return filepath.Clean(path)
+	}
+
+	return filepath.Join(p.sourceDir, filepath.FromSlash(path))
+}
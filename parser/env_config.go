@@ -0,0 +1,26 @@
+package parser
+
+import (
+	"github.com/pkg/errors"
+
+	envconfig_gen "github.com/lonnblad/go-service-doc/envconfig-gen"
+)
+
+// envConfig returns a table with the environment variables of a config
+// struct, read from the struct tags of the fields. The first argument is
+// the directory of the Go package, relative to the source directory, and
+// the second is the name of the struct.
+func (p *Parser) envConfig(d directive) (_ []byte, err error) {
+	if len(d.args) != 2 {
+		err = errors.Errorf("expected a package directory and a struct name, got %d arguments", len(d.args))
+		return
+	}
+
+	table, err := envconfig_gen.BuildMarkdown(p.resolvePath(d.args[0]), d.args[1])
+	if err != nil {
+		err = errors.Wrap(err, "envconfig_gen.BuildMarkdown failed")
+		return
+	}
+
+	return table, nil
+}
@@ -0,0 +1,56 @@
+package parser_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/lonnblad/go-service-doc/parser"
+	"github.com/lonnblad/go-service-doc/utils"
+)
+
+// writeFiles writes the files to a new source directory, which is removed
+// by the caller.
+func writeFiles(t *testing.T, files map[string]string) string {
+	sourceDir, err := ioutil.TempDir("", "go-service-doc")
+	require.NoError(t, err)
+
+	for name, content := range files {
+		path := filepath.Join(sourceDir, filepath.FromSlash(name))
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), os.ModePerm))
+		require.NoError(t, ioutil.WriteFile(path, []byte(content), utils.FilePermission))
+	}
+
+	return sourceDir
+}
+
+// newParser returns a parser of the source directory with page.md as the
+// service file and /docs as the base path.
+func newParser(sourceDir string) *parser.Parser {
+	return parser.NewParser().
+		WithSourceDir(sourceDir).
+		WithOutputDir("generated").
+		WithBasepath("/docs").
+		ServiceFilename("page.md")
+}
+
+// parseFiles writes the files to a source directory and runs the parser
+// returned by newParser, with the options set by configure.
+func parseFiles(t *testing.T, files map[string]string, configure func(*parser.Parser)) *parser.Parser {
+	sourceDir := writeFiles(t, files)
+
+	// nolint: errcheck
+	defer os.RemoveAll(sourceDir)
+
+	mdParser := newParser(sourceDir)
+	if configure != nil {
+		configure(mdParser)
+	}
+
+	mdParser.Run()
+
+	return mdParser
+}
@@ -0,0 +1,64 @@
+package parser
+
+import (
+	"bytes"
+
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v2"
+)
+
+type frontMatter struct {
+	Tags        []string `yaml:"tags"`
+	Title       string   `yaml:"title"`
+	Description string   `yaml:"description"`
+	// Image is the path of the preview image of the page, relative to the
+	// Markdown file, i.e. static/bars.png.
+	Image string `yaml:"image"`
+}
+
+var frontMatterDelimiter = []byte("---")
+
+// parseFrontMatter parses the YAML front matter delimited by --- lines
+// at the start of the Markdown content and returns the content without it.
+func parseFrontMatter(content []byte) (fm frontMatter, markdown []byte, err error) {
+	markdown = content
+
+	firstLine, rest := splitLine(content)
+	if !bytes.Equal(bytes.TrimSpace(firstLine), frontMatterDelimiter) {
+		return
+	}
+
+	var yamlContent []byte
+
+	for len(rest) > 0 {
+		var line []byte
+
+		line, rest = splitLine(rest)
+		if !bytes.Equal(bytes.TrimSpace(line), frontMatterDelimiter) {
+			yamlContent = append(yamlContent, line...)
+			yamlContent = append(yamlContent, '\n')
+
+			continue
+		}
+
+		if err = yaml.Unmarshal(yamlContent, &fm); err != nil {
+			err = errors.Wrap(err, "yaml.Unmarshal failed")
+			return
+		}
+
+		return fm, rest, nil
+	}
+
+	err = errors.New("front matter is missing the closing delimiter")
+
+	return
+}
+
+func splitLine(content []byte) (line, rest []byte) {
+	idx := bytes.IndexByte(content, '\n')
+	if idx == -1 {
+		return content, nil
+	}
+
+	return content[:idx], content[idx+1:]
+}
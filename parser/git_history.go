@@ -0,0 +1,102 @@
+package parser
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+
+	"github.com/lonnblad/go-service-doc/core"
+)
+
+// gitLogFormat prints the commit time and the author of each commit,
+// with the author mapped by the .mailmap of the repository.
+const gitLogFormat = "--format=%at%x09%aN"
+
+// addGitHistory sets when the source of a page was last committed, by
+// whom and the contributors to it. Sources that haven't been committed
+// yet, or that aren't in a git work tree, keep the modification time of
+// the file.
+func addGitHistory(page *core.Page) (err error) {
+	info, err := os.Stat(page.Filepath)
+	if err != nil {
+		err = errors.Wrap(err, "os.Stat failed")
+		return
+	}
+
+	// The history of a file is followed through renames, a directory,
+	// i.e. a Go package, gets the history of the files in it, but not of
+	// the sub directories, like lastModified.
+	dir := filepath.Dir(page.Filepath)
+	args := []string{"log", "--follow", gitLogFormat, "--", filepath.Base(page.Filepath)}
+
+	if info.IsDir() {
+		dir, args = page.Filepath, []string{"log", gitLogFormat, "--"}
+
+		files, err := ioutil.ReadDir(page.Filepath)
+		if err != nil {
+			return errors.Wrap(err, "ioutil.ReadDir failed")
+		}
+
+		for _, f := range files {
+			if !f.IsDir() {
+				args = append(args, f.Name())
+			}
+		}
+
+		if len(args) == 3 {
+			return nil
+		}
+	}
+
+	var stdout, stderr bytes.Buffer
+
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err = cmd.Run(); err != nil {
+		if output := strings.TrimSpace(stderr.String()); output != "" {
+			err = errors.Wrap(err, output)
+		}
+
+		zap.L().With(zap.String("page", page.Filepath), zap.Error(err)).
+			Warn("git log failed, using the modification time of the file")
+
+		return nil
+	}
+
+	contributors := map[string]bool{}
+
+	for _, line := range strings.Split(strings.TrimSpace(stdout.String()), "\n") {
+		fields := strings.SplitN(line, "\t", 2)
+		if len(fields) != 2 {
+			continue
+		}
+
+		if page.LastModifiedBy == "" {
+			timestamp, err := strconv.ParseInt(fields[0], 10, 64)
+			if err != nil {
+				return errors.Wrapf(err, "invalid commit time [%s]", fields[0])
+			}
+
+			page.LastModified = time.Unix(timestamp, 0).UTC()
+			page.LastModifiedBy = fields[1]
+		}
+
+		if !contributors[fields[1]] {
+			contributors[fields[1]] = true
+			page.Contributors = append(page.Contributors, fields[1])
+		}
+	}
+
+	return nil
+}
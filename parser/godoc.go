@@ -0,0 +1,39 @@
+package parser
+
+import (
+	"path/filepath"
+
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+)
+
+// findGoPackages adds a page for each of the Go packages, the page is
+// named after the directory of the package.
+func (p *Parser) findGoPackages() {
+	if len(p.goPackages) > 0 {
+		zap.L().Info("adding Go packages")
+	}
+
+	for _, dir := range p.goPackages {
+		absDir, err := filepath.Abs(dir)
+		if err != nil {
+			p.err = errors.Wrap(err, "filepath.Abs failed")
+			return
+		}
+
+		if err = p.addGeneratedPage(absDir); err != nil {
+			p.err = err
+			return
+		}
+	}
+}
+
+func (p *Parser) isGoPackage(path string) bool {
+	for _, dir := range p.goPackages {
+		if absDir, err := filepath.Abs(dir); err == nil && absDir == path {
+			return true
+		}
+	}
+
+	return false
+}
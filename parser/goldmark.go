@@ -0,0 +1,593 @@
+package parser
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"regexp"
+	"strings"
+
+	"github.com/alecthomas/chroma"
+	chroma_html "github.com/alecthomas/chroma/formatters/html"
+	"github.com/alecthomas/chroma/lexers"
+	"github.com/alecthomas/chroma/styles"
+	"github.com/pkg/errors"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/extension"
+	extension_ast "github.com/yuin/goldmark/extension/ast"
+	goldmark_parser "github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/renderer"
+	goldmark_html "github.com/yuin/goldmark/renderer/html"
+	"github.com/yuin/goldmark/text"
+	"github.com/yuin/goldmark/util"
+
+	"github.com/lonnblad/go-service-doc/config"
+)
+
+const (
+	mermaidLanguage = "mermaid"
+
+	// nodeRendererPriority makes the node renderers of the parser take
+	// precedence over the default HTML renderer of goldmark.
+	nodeRendererPriority = 100
+)
+
+// mermaidScriptPaths are the paths, relative to the static folder, where
+// a Mermaid script that replaces the bundled one is looked up.
+var mermaidScriptPaths = []string{"mermaid.min.js", "mermaid.js"}
+
+var calloutMarkerRegexp = regexp.MustCompile(`^\[!(NOTE|WARNING|DANGER)\][ \t]*`)
+
+// goldmarkRenderer is the CommonMark and GFM compliant markdownRenderer.
+type goldmarkRenderer struct {
+	markdown goldmark.Markdown
+}
+
+func newGoldmarkRenderer(markdownConfig config.Markdown) *goldmarkRenderer {
+	extensions := []goldmark.Extender{extension.Table}
+
+	optionalExtensions := []struct {
+		enabled   bool
+		extension goldmark.Extender
+	}{
+		{markdownConfig.Footnotes, extension.Footnote},
+		{markdownConfig.DefinitionLists, extension.DefinitionList},
+		{markdownConfig.Strikethrough, extension.Strikethrough},
+		{markdownConfig.Autolink, extension.Linkify},
+		{markdownConfig.TaskLists, extension.TaskList},
+	}
+
+	for _, optionalExtension := range optionalExtensions {
+		if optionalExtension.enabled {
+			extensions = append(extensions, optionalExtension.extension)
+		}
+	}
+
+	rendererOptions := []renderer.Option{
+		goldmark_html.WithUnsafe(),
+		renderer.WithNodeRenderers(util.Prioritized(&nodeRenderer{}, nodeRendererPriority)),
+	}
+
+	if markdownConfig.HardLineBreaks {
+		rendererOptions = append(rendererOptions, goldmark_html.WithHardWraps())
+	}
+
+	return &goldmarkRenderer{
+		markdown: goldmark.New(
+			goldmark.WithExtensions(extensions...),
+			goldmark.WithParserOptions(goldmark_parser.WithAttribute()),
+			goldmark.WithRendererOptions(rendererOptions...),
+		),
+	}
+}
+
+func (r *goldmarkRenderer) Render(source []byte, hooks renderHooks) (result renderedMarkdown, err error) {
+	document := r.markdown.Parser().Parse(text.NewReader(source))
+
+	explicitIDs := addHeadingIDs(document, source)
+	addCallouts(document, source)
+	addTaskListItemClasses(document)
+
+	if err = resolveDestinations(document, hooks); err != nil {
+		return
+	}
+
+	if result.MermaidDiagrams, err = addDiagrams(document, source, hooks); err != nil {
+		return
+	}
+
+	buffer := &bytes.Buffer{}
+	if err = r.markdown.Renderer().Render(buffer, source, document); err != nil {
+		err = errors.Wrap(err, "goldmark.Render failed")
+		return
+	}
+
+	result.HTML = buffer.Bytes()
+	result.Blocks = extractBlocks(document, source, explicitIDs)
+	result.Summary = summary(document, source)
+
+	return result, nil
+}
+
+// summary returns the text of the first paragraph at the top level of
+// the document.
+func summary(document ast.Node, source []byte) string {
+	for node := document.FirstChild(); node != nil; node = node.NextSibling() {
+		if paragraph, ok := node.(*ast.Paragraph); ok {
+			if text := inlineText(paragraph, source, nil, nil); text != "" {
+				return text
+			}
+		}
+	}
+
+	return ""
+}
+
+// findNodes returns the nodes for which match returns true, the nodes
+// are collected before they are modified, since goldmark stops walking
+// the siblings of a replaced node.
+func findNodes(document ast.Node, match func(node ast.Node) bool) (nodes []ast.Node) {
+	_ = ast.Walk(document, func(node ast.Node, entering bool) (ast.WalkStatus, error) {
+		if entering && match(node) {
+			nodes = append(nodes, node)
+		}
+
+		return ast.WalkContinue, nil
+	})
+
+	return
+}
+
+// addHeadingIDs adds an ID to the headings without an explicit ID, i.e.
+// {#id}, based on the text of the heading and returns the headings with
+// an explicit ID.
+func addHeadingIDs(document ast.Node, source []byte) (explicitIDs map[ast.Node]bool) {
+	headings := findNodes(document, func(node ast.Node) bool {
+		return node.Kind() == ast.KindHeading
+	})
+
+	explicitIDs = make(map[ast.Node]bool)
+	usedIDs := make(map[string]bool)
+
+	for _, heading := range headings {
+		if id, exists := heading.AttributeString("id"); exists {
+			explicitIDs[heading] = true
+			usedIDs[attributeString(id)] = true
+		}
+	}
+
+	for _, heading := range headings {
+		if explicitIDs[heading] {
+			continue
+		}
+
+		originalID := anchorName(string(heading.Text(source)))
+		if originalID == "" {
+			continue
+		}
+
+		id := originalID
+
+		for n := 1; usedIDs[id]; n++ {
+			id = fmt.Sprintf("%s-%d", originalID, n)
+		}
+
+		usedIDs[id] = true
+		heading.SetAttributeString("id", []byte(id))
+	}
+
+	return explicitIDs
+}
+
+func attributeString(value interface{}) string {
+	switch v := value.(type) {
+	case []byte:
+		return string(v)
+	case string:
+		return v
+	}
+
+	return fmt.Sprint(value)
+}
+
+var kindCallout = ast.NewNodeKind("Callout")
+
+// calloutNode is a block quote starting with a GitHub-style callout
+// marker, i.e. > [!WARNING].
+type calloutNode struct {
+	ast.BaseBlock
+	kind string
+}
+
+func (n *calloutNode) Kind() ast.NodeKind {
+	return kindCallout
+}
+
+func (n *calloutNode) Dump(source []byte, level int) {
+	ast.DumpHelper(n, source, level, map[string]string{"Kind": n.kind}, nil)
+}
+
+// addCallouts replaces the block quotes starting with a callout marker
+// with callouts and removes the markers.
+func addCallouts(document ast.Node, source []byte) {
+	blockquotes := findNodes(document, func(node ast.Node) bool {
+		return node.Kind() == ast.KindBlockquote
+	})
+
+	for _, blockquote := range blockquotes {
+		paragraph := blockquote.FirstChild()
+		if paragraph == nil || paragraph.Kind() != ast.KindParagraph || paragraph.Lines().Len() == 0 {
+			continue
+		}
+
+		firstLine := paragraph.Lines().At(0)
+
+		marker := calloutMarkerRegexp.FindSubmatch(firstLine.Value(source))
+		if marker == nil {
+			continue
+		}
+
+		removeInlineText(paragraph, firstLine.Start+len(marker[0]))
+
+		if !paragraph.HasChildren() {
+			blockquote.RemoveChild(blockquote, paragraph)
+		}
+
+		callout := &calloutNode{kind: strings.ToLower(string(marker[1]))}
+
+		for child := blockquote.FirstChild(); child != nil; child = blockquote.FirstChild() {
+			callout.AppendChild(callout, child)
+		}
+
+		blockquote.Parent().ReplaceChild(blockquote.Parent(), blockquote, callout)
+	}
+}
+
+// removeInlineText removes the leading text of the node up to the stop
+// position in the source.
+func removeInlineText(node ast.Node, stop int) {
+	for child := node.FirstChild(); child != nil; child = node.FirstChild() {
+		textNode, isText := child.(*ast.Text)
+		if !isText || textNode.Segment.Start >= stop {
+			return
+		}
+
+		if textNode.Segment.Stop > stop {
+			textNode.Segment = textNode.Segment.WithStart(stop)
+			return
+		}
+
+		node.RemoveChild(node, child)
+	}
+}
+
+// addTaskListItemClasses adds the task-list-item class to list items
+// starting with a task checkbox.
+func addTaskListItemClasses(document ast.Node) {
+	checkboxes := findNodes(document, func(node ast.Node) bool {
+		return node.Kind() == extension_ast.KindTaskCheckBox
+	})
+
+	for _, checkbox := range checkboxes {
+		if block := checkbox.Parent(); block != nil && block.Parent() != nil && block.Parent().Kind() == ast.KindListItem {
+			block.Parent().SetAttributeString("class", []byte("task-list-item"))
+		}
+	}
+}
+
+// resolveDestinations resolves the destinations of images and links.
+func resolveDestinations(document ast.Node, hooks renderHooks) error {
+	nodes := findNodes(document, func(node ast.Node) bool {
+		return node.Kind() == ast.KindImage || node.Kind() == ast.KindLink
+	})
+
+	for _, node := range nodes {
+		var err error
+
+		switch n := node.(type) {
+		case *ast.Image:
+			destination := string(n.Destination)
+			if destination, err = hooks.resolveDestination(destination, true); err == nil {
+				n.Destination = []byte(destination)
+			}
+		case *ast.Link:
+			destination := string(n.Destination)
+			if destination, err = hooks.resolveDestination(destination, false); err == nil {
+				n.Destination = []byte(destination)
+			}
+		}
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+var kindDiagram = ast.NewNodeKind("Diagram")
+
+// diagramNode is a diagram code block, rendered as an image if the
+// diagram is rendered at build time and as a Mermaid container otherwise.
+type diagramNode struct {
+	ast.BaseBlock
+	language string
+	source   []byte
+	href     string
+}
+
+func (n *diagramNode) Kind() ast.NodeKind {
+	return kindDiagram
+}
+
+func (n *diagramNode) Dump(source []byte, level int) {
+	ast.DumpHelper(n, source, level, map[string]string{"Language": n.language, "Href": n.href}, nil)
+}
+
+// addDiagrams replaces Mermaid code blocks and code blocks rendered to
+// images by the renderDiagram hook with diagrams.
+func addDiagrams(document ast.Node, source []byte, hooks renderHooks) (mermaidDiagrams int, err error) {
+	codeBlocks := findNodes(document, func(node ast.Node) bool {
+		return node.Kind() == ast.KindFencedCodeBlock
+	})
+
+	for _, node := range codeBlocks {
+		codeBlock := node.(*ast.FencedCodeBlock)
+		diagram := &diagramNode{
+			language: codeBlockLanguage(codeBlock, source),
+			source:   codeBlockSource(codeBlock, source),
+		}
+
+		if diagram.language == mermaidLanguage {
+			mermaidDiagrams++
+		} else {
+			var ok bool
+
+			if diagram.href, ok, err = hooks.renderDiagram(diagram.language, diagram.source); err != nil {
+				return
+			}
+
+			if !ok {
+				continue
+			}
+		}
+
+		codeBlock.Parent().ReplaceChild(codeBlock.Parent(), codeBlock, diagram)
+	}
+
+	return
+}
+
+// codeBlockLanguage returns the language of a fenced code block, i.e.
+// the first word of the info string.
+func codeBlockLanguage(node ast.Node, source []byte) string {
+	codeBlock, ok := node.(*ast.FencedCodeBlock)
+	if !ok {
+		return ""
+	}
+
+	if language := codeBlock.Language(source); language != nil {
+		return string(language)
+	}
+
+	return ""
+}
+
+func codeBlockSource(node ast.Node, source []byte) []byte {
+	var buffer bytes.Buffer
+
+	lines := node.Lines()
+	for idx := 0; idx < lines.Len(); idx++ {
+		line := lines.At(idx)
+		buffer.Write(line.Value(source))
+	}
+
+	return buffer.Bytes()
+}
+
+// nodeRenderer renders code blocks with chroma and renders the callouts
+// and diagrams.
+type nodeRenderer struct{}
+
+func (r *nodeRenderer) RegisterFuncs(reg renderer.NodeRendererFuncRegisterer) {
+	reg.Register(ast.KindFencedCodeBlock, r.renderCodeBlock)
+	reg.Register(ast.KindCodeBlock, r.renderCodeBlock)
+	reg.Register(kindCallout, r.renderCallout)
+	reg.Register(kindDiagram, r.renderDiagram)
+}
+
+func (r *nodeRenderer) renderCodeBlock(w util.BufWriter, source []byte, node ast.Node, entering bool) (ast.WalkStatus, error) {
+	if !entering {
+		return ast.WalkContinue, nil
+	}
+
+	code := string(codeBlockSource(node, source))
+
+	if err := highlight(w, codeBlockLanguage(node, source), code); err != nil {
+		_, _ = fmt.Fprintf(w, "<pre><code>%s</code></pre>\n", html.EscapeString(code))
+	}
+
+	return ast.WalkSkipChildren, nil
+}
+
+// highlight renders the code with chroma, the language is detected from
+// the code if it isn't set.
+func highlight(w util.BufWriter, language, code string) error {
+	var lexer chroma.Lexer
+
+	if language != "" {
+		lexer = lexers.Get(language)
+	} else {
+		lexer = lexers.Analyse(code)
+	}
+
+	if lexer == nil {
+		lexer = lexers.Fallback
+	}
+
+	iterator, err := lexer.Tokenise(nil, code)
+	if err != nil {
+		return errors.Wrap(err, "lexer.Tokenise failed")
+	}
+
+	buffer := &bytes.Buffer{}
+	if err = chroma_html.New().Format(buffer, styles.Monokai, iterator); err != nil {
+		return errors.Wrap(err, "formatter.Format failed")
+	}
+
+	_, _ = w.Write(buffer.Bytes())
+
+	return nil
+}
+
+func (r *nodeRenderer) renderCallout(w util.BufWriter, source []byte, node ast.Node, entering bool) (ast.WalkStatus, error) {
+	callout := node.(*calloutNode)
+
+	if entering {
+		_, _ = fmt.Fprintf(w, "<div class=\"callout callout-%s\">\n<p class=\"callout-title\">%s</p>\n", callout.kind, strings.Title(callout.kind))
+	} else {
+		_, _ = w.WriteString("</div>\n")
+	}
+
+	return ast.WalkContinue, nil
+}
+
+func (r *nodeRenderer) renderDiagram(w util.BufWriter, source []byte, node ast.Node, entering bool) (ast.WalkStatus, error) {
+	diagram := node.(*diagramNode)
+
+	if !entering {
+		return ast.WalkContinue, nil
+	}
+
+	if diagram.href != "" {
+		_, _ = fmt.Fprintf(w,
+			"<p><img src=\"%s\" alt=\"%s\"></p>\n",
+			html.EscapeString(diagram.href), html.EscapeString(diagram.language+" diagram"),
+		)
+	} else {
+		_, _ = fmt.Fprintf(w, "<div class=\"mermaid\">\n%s</div>\n", html.EscapeString(string(diagram.source)))
+	}
+
+	return ast.WalkSkipChildren, nil
+}
+
+// extractBlocks returns the headings and the searchable content of the
+// document, paragraphs and table rows are one piece of content each and
+// footnotes are added to the content where they are referenced.
+func extractBlocks(document ast.Node, source []byte, explicitIDs map[ast.Node]bool) (blocks []markdownBlock) {
+	footnotes := make(map[int]ast.Node)
+
+	for _, node := range findNodes(document, func(node ast.Node) bool { return node.Kind() == extension_ast.KindFootnote }) {
+		footnotes[node.(*extension_ast.Footnote).Index] = node
+	}
+
+	_ = ast.Walk(document, func(node ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering {
+			return ast.WalkContinue, nil
+		}
+
+		var block markdownBlock
+
+		switch n := node.(type) {
+		case *ast.Heading:
+			id, _ := n.AttributeString("id")
+
+			block.Heading = &markdownHeading{
+				Level:      n.Level,
+				ID:         attributeString(id),
+				Title:      inlineText(n, source, nil, nil),
+				ExplicitID: explicitIDs[n],
+			}
+		case *ast.Paragraph, *ast.TextBlock, *extension_ast.DefinitionTerm:
+			var footnoteIndexes []int
+
+			addContent(&block, inlineText(n, source, &block.Code, &footnoteIndexes))
+
+			for _, index := range footnoteIndexes {
+				if footnote, exists := footnotes[index]; exists {
+					addContent(&block, inlineText(footnote, source, &block.Code, nil))
+				}
+			}
+		case *extension_ast.TableHeader, *extension_ast.TableRow:
+			var cells []string
+
+			for cell := n.FirstChild(); cell != nil; cell = cell.NextSibling() {
+				cells = append(cells, inlineText(cell, source, &block.Code, nil))
+			}
+
+			addContent(&block, strings.Join(cells, " | "))
+		case *ast.FencedCodeBlock, *ast.CodeBlock:
+			if language := codeBlockLanguage(n, source); language != "" {
+				block.Code = append(block.Code, language)
+			}
+
+			block.Code = append(block.Code, string(codeBlockSource(n, source)))
+		case *diagramNode:
+			block.Code = append(block.Code, n.language, string(n.source))
+		case *extension_ast.FootnoteList, *ast.HTMLBlock:
+			return ast.WalkSkipChildren, nil
+		default:
+			return ast.WalkContinue, nil
+		}
+
+		if block.Heading != nil || len(block.Content) > 0 || len(block.Code) > 0 {
+			blocks = append(blocks, block)
+		}
+
+		return ast.WalkSkipChildren, nil
+	})
+
+	return blocks
+}
+
+func addContent(block *markdownBlock, content string) {
+	if content = strings.TrimSpace(content); content != "" {
+		block.Content = append(block.Content, content)
+	}
+}
+
+// inlineText returns the text of the inline nodes below node, i.e. the
+// text of links, emphasis and code spans, the code spans are also added
+// to code and the indexes of referenced footnotes to footnoteIndexes.
+func inlineText(node ast.Node, source []byte, code *[]string, footnoteIndexes *[]int) string {
+	var text strings.Builder
+
+	_ = ast.Walk(node, func(child ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering {
+			return ast.WalkContinue, nil
+		}
+
+		switch n := child.(type) {
+		case *ast.Text:
+			text.Write(n.Segment.Value(source))
+
+			if n.SoftLineBreak() || n.HardLineBreak() {
+				text.WriteString(" ")
+			}
+		case *ast.String:
+			text.Write(n.Value)
+		case *ast.AutoLink:
+			text.Write(n.Label(source))
+		case *ast.CodeSpan:
+			codeSpan := string(n.Text(source))
+			text.WriteString(codeSpan)
+
+			if code != nil {
+				*code = append(*code, codeSpan)
+			}
+
+			return ast.WalkSkipChildren, nil
+		case *extension_ast.FootnoteLink:
+			if footnoteIndexes != nil {
+				*footnoteIndexes = append(*footnoteIndexes, n.Index)
+			}
+		case *ast.RawHTML:
+			return ast.WalkSkipChildren, nil
+		}
+
+		return ast.WalkContinue, nil
+	})
+
+	return strings.TrimSpace(text.String())
+}
@@ -0,0 +1,173 @@
+package parser
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+
+	"github.com/lonnblad/go-service-doc/core"
+	image_opt "github.com/lonnblad/go-service-doc/image-opt"
+	"github.com/lonnblad/go-service-doc/utils"
+)
+
+// imageSizes tells the browser the width of an image in the page, which
+// is at most the width of the doc container.
+const imageSizes = "(max-width: 1000px) 100vw, 1000px"
+
+var (
+	imgRegexp           = regexp.MustCompile(`<img src="([^"]*)"([^>]*)>`)
+	htmlAttributeRegexp = regexp.MustCompile(`([a-zA-Z-]+)(?:\s*=\s*(?:"[^"]*"|'[^']*'|[^\s"'>]+))?`)
+)
+
+// imageAttributeGroups contains the attributes added to the images in the
+// order they are added, the attributes of a group are only added if none
+// of them are set on the image, i.e. a height isn't added to an image
+// with a width.
+var imageAttributeGroups = [][]string{{"width", "height"}, {"loading"}, {"srcset", "sizes"}}
+
+// optimizeImages recompresses the PNG and JPEG static files and adds the
+// downscaled variants of them to the static files.
+func (p *Parser) optimizeImages() {
+	if !p.imageOptimization {
+		return
+	}
+
+	zap.L().Info("optimizing images")
+
+	var originalSize, optimizedSize int
+
+	staticFiles := p.staticFiles
+
+	for idx, file := range staticFiles {
+		if !image_opt.Supported(file.ContentType) {
+			continue
+		}
+
+		result, err := image_opt.Optimize(file.Content, file.ContentType)
+		if err != nil {
+			p.err = errors.Wrapf(err, "image_opt.Optimize failed for [%s]", file.Href)
+			return
+		}
+
+		originalSize += len(file.Content)
+		optimizedSize += len(result.Content)
+
+		file.Content = result.Content
+		file.Fingerprint = utils.Fingerprint(file.Content)
+		p.staticFiles[idx] = file
+
+		var srcset []string
+
+		for _, variant := range result.Variants {
+			variantFile := imageVariant(file, variant)
+			variantFile.Name = p.uniqueStaticFileName(variantFile.Name)
+
+			if source, exists := p.staticFileSources[variantFile.Href]; exists {
+				p.err = errors.Errorf("static file [%s] has the same href as a variant of [%s], [%s]", source, file.Href, variantFile.Href)
+				return
+			}
+
+			p.staticFileSources[variantFile.Href] = variantFile.Href
+			p.staticFiles = append(p.staticFiles, variantFile)
+
+			srcset = append(srcset, fmt.Sprintf("%s %dw", variantFile.FingerprintedHref(), variant.Width))
+		}
+
+		attributes := map[string]string{
+			"width":   fmt.Sprint(result.Width),
+			"height":  fmt.Sprint(result.Height),
+			"loading": "lazy",
+		}
+
+		if len(srcset) > 0 {
+			srcset = append(srcset, fmt.Sprintf("%s %dw", file.FingerprintedHref(), result.Width))
+			attributes["srcset"] = strings.Join(srcset, ", ")
+			attributes["sizes"] = imageSizes
+		}
+
+		p.imageAttributes[file.FingerprintedHref()] = attributes
+	}
+
+	zap.L().With(
+		zap.Int("original_bytes", originalSize),
+		zap.Int("optimized_bytes", optimizedSize),
+		zap.Int("saved_bytes", originalSize-optimizedSize),
+	).Info("optimized images")
+}
+
+// imageVariant creates a static file for a downscaled variant of an image,
+// i.e. bars.png will be bars-480w.png.
+func imageVariant(file core.File, variant image_opt.Image) core.File {
+	suffix := fmt.Sprintf("-%dw", variant.Width)
+
+	file.Href = addSuffix(file.Href, suffix)
+	file.Path = addSuffix(file.Path, suffix)
+	file.Name += utils.ConvertToCamelCase(suffix)
+	file.Content = variant.Content
+	file.Fingerprint = utils.Fingerprint(file.Content)
+
+	return file
+}
+
+func addSuffix(filepath, suffix string) string {
+	ext := path.Ext(filepath)
+	return strings.TrimSuffix(filepath, ext) + suffix + ext
+}
+
+// addImageAttributes adds the size and srcset attributes of optimized
+// images and lazy loading to the images in the HTML of the pages, the
+// attributes already set on an image, i.e. in raw HTML, are kept.
+func (p *Parser) addImageAttributes() {
+	if !p.imageOptimization {
+		return
+	}
+
+	for idx, page := range p.pages {
+		page.Markdown = imgRegexp.ReplaceAllStringFunc(page.Markdown, func(img string) string {
+			match := imgRegexp.FindStringSubmatch(img)
+			src, rest := match[1], match[2]
+
+			attributes, exists := p.imageAttributes[src]
+			if !exists {
+				attributes = map[string]string{"loading": "lazy"}
+			}
+
+			existing := map[string]bool{}
+			for _, attribute := range htmlAttributeRegexp.FindAllStringSubmatch(rest, -1) {
+				existing[strings.ToLower(attribute[1])] = true
+			}
+
+			var added strings.Builder
+
+			for _, group := range imageAttributeGroups {
+				if containsAny(existing, group) {
+					continue
+				}
+
+				for _, name := range group {
+					if value, exists := attributes[name]; exists {
+						fmt.Fprintf(&added, ` %s="%s"`, name, value)
+					}
+				}
+			}
+
+			return `<img src="` + src + `"` + added.String() + rest + ">"
+		})
+
+		p.pages[idx] = page
+	}
+}
+
+func containsAny(set map[string]bool, names []string) bool {
+	for _, name := range names {
+		if set[name] {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,91 @@
+package parser_test
+
+import (
+	"bytes"
+	"image"
+	"image/png"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lonnblad/go-service-doc/parser"
+)
+
+func Test_Parser_ImageAttributes(t *testing.T) {
+	var content bytes.Buffer
+	require.NoError(t, png.Encode(&content, image.NewGray(image.Rect(0, 0, 1000, 10))))
+
+	const sizes = `sizes="(max-width: 1000px) 100vw, 1000px"`
+
+	testcases := []struct {
+		name     string
+		markdown string
+		expected func(hrefs map[string]string) string
+		excluded []string
+	}{
+		{
+			name:     "optimized image",
+			markdown: "![Bars](static/bars.png)",
+			expected: func(hrefs map[string]string) string {
+				return `<img src="` + hrefs["/docs/static/bars.png"] + `" width="1000" height="10" loading="lazy" srcset="` +
+					hrefs["/docs/static/bars-480w.png"] + ` 480w, ` + hrefs["/docs/static/bars-960w.png"] + ` 960w, ` +
+					hrefs["/docs/static/bars.png"] + ` 1000w" ` + sizes + ` alt="Bars">`
+			},
+		},
+		{
+			name:     "image that isn't optimized",
+			markdown: "![Bars](static/bars.svg)",
+			expected: func(hrefs map[string]string) string {
+				return `<img src="` + hrefs["/docs/static/bars.svg"] + `" loading="lazy" alt="Bars">`
+			},
+		},
+		{
+			name:     "raw HTML with a size and loading",
+			markdown: `<img src="/docs/static/bars.png" width="500" loading="eager">`,
+			expected: func(hrefs map[string]string) string {
+				return `<img src="` + hrefs["/docs/static/bars.png"] + `" srcset="` +
+					hrefs["/docs/static/bars-480w.png"] + ` 480w, ` + hrefs["/docs/static/bars-960w.png"] + ` 960w, ` +
+					hrefs["/docs/static/bars.png"] + ` 1000w" ` + sizes + ` width="500" loading="eager">`
+			},
+			excluded: []string{`height=`, `loading="lazy"`},
+		},
+		{
+			name:     "raw HTML with a srcset",
+			markdown: `<img src="/docs/static/bars.png" srcset="/bars@2x.png 2x">`,
+			expected: func(hrefs map[string]string) string {
+				return `<img src="` + hrefs["/docs/static/bars.png"] + `" width="1000" height="10" loading="lazy" srcset="/bars@2x.png 2x">`
+			},
+			excluded: []string{"sizes="},
+		},
+	}
+
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			files := map[string]string{
+				"page.md":         "# Bars {#bars}\n\n" + tc.markdown + "\n",
+				"static/bars.png": content.String(),
+				"static/bars.svg": "<svg></svg>",
+			}
+
+			mdParser := parseFiles(t, files, func(mdParser *parser.Parser) {
+				mdParser.WithImageOptimization(true)
+			})
+			require.NoError(t, mdParser.Error())
+			require.Len(t, mdParser.Pages(), 1)
+
+			hrefs := map[string]string{}
+			for _, file := range mdParser.StaticFiles() {
+				hrefs[file.Href] = file.FingerprintedHref()
+			}
+
+			markdown := mdParser.Pages()[0].Markdown
+			assert.Contains(t, markdown, tc.expected(hrefs))
+
+			for _, excluded := range tc.excluded {
+				assert.NotContains(t, markdown, excluded)
+			}
+		})
+	}
+}
@@ -0,0 +1,41 @@
+package parser
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// include returns the content of a Markdown file, usually a partial
+// prefixed with _, with the directives of the file expanded. The path is
+// relative to the source directory.
+func (p *Parser) include(d directive) (_ []byte, err error) {
+	if len(d.args) != 1 {
+		err = errors.Errorf("expected a path, got %d arguments", len(d.args))
+		return
+	}
+
+	path := p.resolvePath(d.args[0])
+
+	includes := append([]string{}, d.includes...)
+	if len(includes) == 0 {
+		includes = append(includes, filepath.Clean(d.filepath))
+	}
+
+	for _, included := range includes {
+		if included == path {
+			err = errors.Errorf("include cycle [%s -> %s]", strings.Join(includes, " -> "), path)
+			return
+		}
+	}
+
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		err = errors.Wrap(err, "ioutil.ReadFile failed")
+		return
+	}
+
+	return p.expandDirectives(path, content, append(includes, path))
+}
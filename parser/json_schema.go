@@ -0,0 +1,25 @@
+package parser
+
+import (
+	"github.com/pkg/errors"
+
+	jsonschema_gen "github.com/lonnblad/go-service-doc/jsonschema-gen"
+)
+
+// jsonSchema returns tables with the properties of a JSON Schema and an
+// example payload. The path is relative to the source directory and so
+// are the files the schema references.
+func (p *Parser) jsonSchema(d directive) (_ []byte, err error) {
+	if len(d.args) != 1 {
+		err = errors.Errorf("expected a path, got %d arguments", len(d.args))
+		return
+	}
+
+	tables, err := jsonschema_gen.BuildMarkdown(p.sourceDir, p.resolvePath(d.args[0]))
+	if err != nil {
+		err = errors.Wrapf(err, "jsonschema_gen.BuildMarkdown failed for [%s]", d.args[0])
+		return
+	}
+
+	return tables, nil
+}
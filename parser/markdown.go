@@ -0,0 +1,153 @@
+package parser
+
+import (
+	"fmt"
+	"unicode"
+
+	"github.com/pkg/errors"
+
+	"github.com/lonnblad/go-service-doc/core"
+)
+
+// markdownRenderer converts Markdown to HTML and extracts the headings
+// and the searchable content, so that the rest of the parser doesn't
+// depend on the Markdown engine.
+type markdownRenderer interface {
+	Render(source []byte, hooks renderHooks) (renderedMarkdown, error)
+}
+
+// renderHooks are called by the markdownRenderer for the parts of the
+// rendering that depend on the state of the parser.
+type renderHooks struct {
+	// resolveDestination returns the destination to use for an image, or
+	// a link if image is false.
+	resolveDestination func(destination string, image bool) (string, error)
+	// renderDiagram renders a code block to an image and returns the href
+	// of the image, ok is false if the language isn't a diagram language.
+	renderDiagram func(language string, source []byte) (href string, ok bool, err error)
+}
+
+type renderedMarkdown struct {
+	HTML []byte
+	// Blocks are the headings and the searchable content of the Markdown
+	// in document order.
+	Blocks          []markdownBlock
+	MermaidDiagrams int
+	// Summary is the text of the first paragraph that isn't nested in a
+	// list, a quote or a table.
+	Summary string
+}
+
+// markdownBlock is either a heading or the searchable content of a
+// paragraph, a table row or a code block.
+type markdownBlock struct {
+	Heading *markdownHeading
+	Content []string
+	Code    []string
+}
+
+type markdownHeading struct {
+	Level int
+	ID    string
+	Title string
+	// ExplicitID is true if the ID is set in the Markdown, i.e. {#id}.
+	ExplicitID bool
+}
+
+// anchorName converts the text of a heading to an ID, i.e. Code Examples
+// will be code-examples.
+func anchorName(text string) string {
+	var (
+		anchor     []rune
+		futureDash bool
+	)
+
+	for _, r := range text {
+		if !unicode.IsLetter(r) && !unicode.IsNumber(r) {
+			futureDash = true
+			continue
+		}
+
+		if futureDash && len(anchor) > 0 {
+			anchor = append(anchor, '-')
+		}
+
+		futureDash = false
+		anchor = append(anchor, unicode.ToLower(r))
+	}
+
+	return string(anchor)
+}
+
+// buildMenu adds the level 1 and 2 headings with an explicit ID to the
+// headers of the page.
+func (p *Parser) buildMenu(page *core.Page, blocks []markdownBlock) error {
+	for _, block := range blocks {
+		heading := block.Heading
+		if heading == nil || heading.Title == "" || heading.Level > 2 || !heading.ExplicitID {
+			continue
+		}
+
+		link := fmt.Sprintf("%s#%s", page.WebPath, heading.ID)
+		if exists := p.uniqueLinks[link]; exists {
+			return errors.Errorf("link already exists, [%s]", link)
+		}
+
+		p.uniqueLinks[link] = true
+		h := core.Header{Title: heading.Title, Link: link}
+
+		if heading.Level == 1 || len(page.Headers) == 0 {
+			if heading.Level == 1 && p.serviceName == page.Name && p.serviceTitle == "" {
+				p.serviceTitle = h.Title
+			}
+
+			page.Headers = append(page.Headers, h)
+
+			continue
+		}
+
+		idx := len(page.Headers) - 1
+		page.Headers[idx].Headers = append(page.Headers[idx].Headers, h)
+	}
+
+	return nil
+}
+
+// buildIndexDocuments creates one search index document per heading with
+// the content up to the next heading, the context of a document is the
+// titles of the headings it's nested in.
+func buildIndexDocuments(page *core.Page, blocks []markdownBlock) {
+	var currentDoc core.IndexDocument
+
+	for _, block := range blocks {
+		if block.Heading == nil {
+			currentDoc.Content = append(currentDoc.Content, block.Content...)
+			currentDoc.Code = append(currentDoc.Code, block.Code...)
+
+			continue
+		}
+
+		if currentDoc.ID != "" {
+			page.IndexDocuments = append(page.IndexDocuments, currentDoc)
+		}
+
+		ctxIdx := block.Heading.Level - 1
+		if len(currentDoc.Context) < ctxIdx {
+			ctxIdx = len(currentDoc.Context)
+		}
+
+		context := make([]string, ctxIdx+1)
+		copy(context, currentDoc.Context[:ctxIdx])
+		context[ctxIdx] = block.Heading.Title
+
+		currentDoc = core.IndexDocument{
+			ID:      block.Heading.ID,
+			Link:    fmt.Sprintf("%s#%s", page.WebPath, block.Heading.ID),
+			Context: context,
+		}
+	}
+
+	if currentDoc.ID != "" {
+		page.IndexDocuments = append(page.IndexDocuments, currentDoc)
+	}
+}
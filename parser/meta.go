@@ -0,0 +1,72 @@
+package parser
+
+import (
+	"strings"
+	"unicode/utf8"
+
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+
+	"github.com/lonnblad/go-service-doc/core"
+)
+
+// maxDescriptionLength is the length search engines show of a
+// description, longer descriptions derived from the content are cut at
+// a word boundary.
+const maxDescriptionLength = 160
+
+// addPageMeta sets the title, the description and the preview image of
+// a page. The title and the description are taken from the front matter,
+// or else from the first heading and the first paragraph of the page. The
+// preview image is only set with a base URL.
+func (p *Parser) addPageMeta(page *core.Page, fm frontMatter, summary string) (err error) {
+	page.Title = fm.Title
+	if page.Title == "" && len(page.Headers) > 0 {
+		page.Title = page.Headers[0].Title
+	}
+
+	page.Description = fm.Description
+	if page.Description == "" {
+		page.Description = truncateDescription(summary)
+	}
+
+	if fm.Image == "" {
+		return nil
+	}
+
+	href, err := p.relativeStaticFileHref(*page, fm.Image)
+	if err != nil {
+		return errors.Wrapf(err, "invalid image [%s]", fm.Image)
+	}
+
+	// Sites that show previews require an absolute URL of the image.
+	if p.baseURL == "" {
+		zap.L().With(zap.String("page", page.Filepath), zap.String("image", fm.Image)).
+			Warn("skipped the preview image, it requires the base URL to be set")
+
+		return nil
+	}
+
+	page.Image = strings.TrimSuffix(p.baseURL, "/") + href
+
+	return nil
+}
+
+// truncateDescription cuts the description at the last word boundary
+// before maxDescriptionLength and appends an ellipsis.
+func truncateDescription(description string) string {
+	description = strings.Join(strings.Fields(description), " ")
+
+	if utf8.RuneCountInString(description) <= maxDescriptionLength {
+		return description
+	}
+
+	runes := []rune(description)[:maxDescriptionLength-1]
+	truncated := string(runes)
+
+	if idx := strings.LastIndex(truncated, " "); idx > 0 {
+		truncated = truncated[:idx]
+	}
+
+	return strings.TrimRight(truncated, " ,.;:") + "…"
+}
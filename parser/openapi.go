@@ -0,0 +1,56 @@
+package parser
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+
+	openapi_gen "github.com/lonnblad/go-service-doc/openapi-gen"
+)
+
+// apiSpecExtensions are the extensions of the files that are checked for
+// OpenAPI 3 and Swagger 2 documents.
+var apiSpecExtensions = map[string]bool{".yaml": true, ".yml": true, ".json": true}
+
+// findAPISpecs adds a page for each OpenAPI 3 or Swagger 2 document in
+// the source directory, other YAML and JSON files are ignored.
+func (p *Parser) findAPISpecs() {
+	zap.L().Info("search for API specs")
+
+	files, err := ioutil.ReadDir(p.sourceDir + "/")
+	if err != nil {
+		p.err = errors.Wrap(err, "ioutil.ReadDir failed")
+		return
+	}
+
+	for _, f := range files {
+		ext := filepath.Ext(f.Name())
+		if f.IsDir() || !apiSpecExtensions[ext] || strings.HasPrefix(f.Name(), "_") {
+			continue
+		}
+
+		path := p.sourceDir + "/" + f.Name()
+
+		content, err := ioutil.ReadFile(path)
+		if err != nil {
+			p.err = errors.Wrap(err, "ioutil.ReadFile failed")
+			return
+		}
+
+		if !openapi_gen.IsSpec(content) {
+			continue
+		}
+
+		if err = p.addGeneratedPage(p.sourceDir + "/" + f.Name()); err != nil {
+			p.err = err
+			return
+		}
+	}
+}
+
+func isAPISpec(path string) bool {
+	return apiSpecExtensions[filepath.Ext(path)]
+}
@@ -1,39 +1,67 @@
 package parser
 
 import (
-	"fmt"
 	"io/ioutil"
+	"path/filepath"
 	"regexp"
 	"strings"
 
-	"github.com/Depado/bfchroma"
 	"github.com/pkg/errors"
-	"github.com/russross/blackfriday/v2"
 	"go.uber.org/zap"
 
+	changelog_gen "github.com/lonnblad/go-service-doc/changelog-gen"
+	"github.com/lonnblad/go-service-doc/config"
 	"github.com/lonnblad/go-service-doc/core"
+	godoc_gen "github.com/lonnblad/go-service-doc/godoc-gen"
 	html_gen "github.com/lonnblad/go-service-doc/html-gen"
+	openapi_gen "github.com/lonnblad/go-service-doc/openapi-gen"
+	proto_gen "github.com/lonnblad/go-service-doc/proto-gen"
 	"github.com/lonnblad/go-service-doc/utils"
 )
 
 type Parser struct {
-	sourceDir       string
-	outputDir       string
-	basepath        string
-	serviceFilename string
-	serviceName     string
-	serviceTitle    string
-	uniqueLinks     map[string]bool
-	pages           core.Pages
-	staticFiles     core.Files
-	searchPage      string
-	faviconHref     string
-	err             error
+	sourceDir         string
+	outputDir         string
+	basepath          string
+	serviceFilename   string
+	serviceName       string
+	serviceTitle      string
+	uniqueLinks       map[string]bool
+	pages             core.Pages
+	staticFiles       core.Files
+	staticFileHrefs   map[string]string
+	staticFileSources map[string]string
+	staticFileNames   map[string]bool
+	imageOptimization bool
+	imageAttributes   map[string]map[string]string
+	searchPage        string
+	faviconHref       string
+	mermaidScriptHref string
+	mermaidPages      map[string]bool
+	diagramRenderers  map[string]DiagramRenderer
+	markdownConfig    config.Markdown
+	goPackages        []string
+	changelog         config.Changelog
+	baseURL           string
+	gitHistory        bool
+	feedTitle         string
+	feedHref          string
+	generatedFiles    core.Files
+	err               error
 }
 
 func NewParser() *Parser {
 	p := Parser{
-		uniqueLinks: make(map[string]bool),
+		uniqueLinks:       make(map[string]bool),
+		staticFileHrefs:   make(map[string]string),
+		staticFileSources: make(map[string]string),
+		staticFileNames:   make(map[string]bool),
+		imageAttributes:   make(map[string]map[string]string),
+		mermaidPages:      make(map[string]bool),
+		diagramRenderers: map[string]DiagramRenderer{
+			"dot": CommandDiagramRenderer(DefaultDotCommand),
+		},
+		markdownConfig: config.Default().Markdown,
 	}
 
 	return &p
@@ -54,6 +82,55 @@ func (se *Parser) WithBasepath(basepath string) *Parser {
 	return se
 }
 
+// WithImageOptimization enables recompression of PNG and JPEG images and
+// generation of downscaled variants used in the srcset of the images.
+func (se *Parser) WithImageOptimization(optimizeImages bool) *Parser {
+	se.imageOptimization = optimizeImages
+	return se
+}
+
+// WithDiagramRenderer sets the renderer used to render code blocks of
+// the language to SVG images at build time.
+func (se *Parser) WithDiagramRenderer(language string, renderer DiagramRenderer) *Parser {
+	se.diagramRenderers[language] = renderer
+	return se
+}
+
+// WithMarkdownConfig sets the Markdown extensions to enable.
+func (se *Parser) WithMarkdownConfig(markdownConfig config.Markdown) *Parser {
+	se.markdownConfig = markdownConfig
+	return se
+}
+
+// WithGoPackages sets the directories of the Go packages to generate
+// reference pages for.
+func (se *Parser) WithGoPackages(dirs []string) *Parser {
+	se.goPackages = dirs
+	return se
+}
+
+// WithChangelog sets the path of a CHANGELOG.md outside of the source
+// directory and enables the feed of the releases.
+func (se *Parser) WithChangelog(changelog config.Changelog) *Parser {
+	se.changelog = changelog
+	return se
+}
+
+// WithBaseURL sets the URL the documentation is published at, without
+// the base path, which is used for absolute links and enables the
+// sitemap and the canonical URLs of the pages.
+func (se *Parser) WithBaseURL(baseURL string) *Parser {
+	se.baseURL = baseURL
+	return se
+}
+
+// WithGitHistory enables reading when each page was last changed and by
+// whom from the git history of its source.
+func (se *Parser) WithGitHistory(gitHistory bool) *Parser {
+	se.gitHistory = gitHistory
+	return se
+}
+
 func (se *Parser) ServiceFilename(serviceFilename string) *Parser {
 	se.serviceFilename = serviceFilename
 	return se
@@ -72,17 +149,42 @@ func (p *Parser) StaticFiles() core.Files {
 	return p.staticFiles
 }
 
+// GeneratedFiles returns the files generated from the pages, i.e. the
+// changelog feed and the sitemap.
+func (p *Parser) GeneratedFiles() core.Files {
+	return p.generatedFiles
+}
+
 func (p *Parser) SearchPage() string {
 	return p.searchPage
 }
 
 func (p *Parser) Run() {
-	p.findMDFiles()
-	p.findStaticFiles()
-	p.parseMarkdown()
-	p.enrichIndexDocumentsWithHTML()
-	p.buildHTMLPages()
-	p.buildSearchPage()
+	steps := []func(){
+		p.findMDFiles,
+		p.findAPISpecs,
+		p.findProtoFiles,
+		p.findGoPackages,
+		p.findChangelog,
+		p.findStaticFiles,
+		p.parseMarkdown,
+		p.optimizeImages,
+		p.fingerprintStaticFileReferences,
+		p.addImageAttributes,
+		p.enrichIndexDocumentsWithHTML,
+		p.buildChangelogFeed,
+		p.buildHTMLPages,
+		p.buildSitemap,
+		p.buildSearchPage,
+	}
+
+	for _, step := range steps {
+		if p.err != nil {
+			return
+		}
+
+		step()
+	}
 }
 
 func (p *Parser) findMDFiles() {
@@ -95,13 +197,13 @@ func (p *Parser) findMDFiles() {
 	}
 
 	for _, f := range files {
-		if f.IsDir() || !strings.HasSuffix(f.Name(), ".md") {
+		// Files prefixed with _ are partials, which are only included.
+		if f.IsDir() || !strings.HasSuffix(f.Name(), ".md") || strings.HasPrefix(f.Name(), "_") {
 			continue
 		}
 
 		page := core.Page{}
-		page.Name = strings.ReplaceAll(f.Name(), ".md", "")
-		page.Name = utils.ConvertToCamelCase(page.Name)
+		page.Name = pageName(f.Name())
 
 		if f.Name() != p.serviceFilename {
 			page.WebPath = p.basepath + "/" + utils.ConvertToKebabCase(page.Name)
@@ -115,142 +217,154 @@ func (p *Parser) findMDFiles() {
 	}
 }
 
-func (p *Parser) parseMarkdown() {
-	for idx, pg := range p.pages {
-		page := pg
-		zap.L().With(zap.String("page", page.Name)).Info("parsing markdown")
+// addGeneratedPage adds a page for a file or a directory that isn't a
+// Markdown file, i.e. bars-api.yaml is added as bars-api.
+func (p *Parser) addGeneratedPage(path string) error {
+	name := filepath.Base(path)
 
-		content, err := ioutil.ReadFile(page.Filepath)
-		if err != nil {
-			p.err = errors.Wrap(err, "ioutil.ReadFile failed")
-			return
+	page := core.Page{}
+	page.Name = pageName(name)
+	page.WebPath = p.basepath + "/" + utils.ConvertToKebabCase(page.Name)
+	page.Filepath = path
+
+	// The HTML file of a page is named after the source of the page.
+	for _, existing := range p.pages {
+		if existing.WebPath == page.WebPath || pageFilename(existing) == pageFilename(page) {
+			return errors.Errorf("[%s] and [%s] would generate the same page [%s]", existing.Filepath, page.Filepath, page.WebPath)
 		}
+	}
 
-		// Convert Markdown to HTML
-		exts := blackfriday.NoIntraEmphasis |
-			blackfriday.AutoHeadingIDs |
-			blackfriday.HeadingIDs |
-			blackfriday.FencedCode |
-			blackfriday.HardLineBreak |
-			blackfriday.Tables
-
-		markdown := blackfriday.Run(
-			content,
-			blackfriday.WithRenderer(bfchroma.NewRenderer()),
-			blackfriday.WithExtensions(exts),
-		)
-		page.Markdown = string(markdown)
-
-		// Build Menu from Markdown
-		menuNode := blackfriday.New(blackfriday.WithExtensions(blackfriday.HeadingIDs)).Parse(content)
-		menuNode.Walk(p.menuWalker(&page))
-
-		// Build Search Index Documents from Markdown
-		searchNode := blackfriday.New(blackfriday.WithExtensions(blackfriday.AutoHeadingIDs | blackfriday.HeadingIDs)).Parse(content)
-		searchNode.Walk(p.searchWalker(&page))
+	p.pages = append(p.pages, page)
 
-		p.pages[idx] = page
-	}
+	return nil
 }
 
-func (p *Parser) menuWalker(page *core.Page) blackfriday.NodeVisitor {
-	return func(node *blackfriday.Node, entering bool) blackfriday.WalkStatus {
-		if node.Type != blackfriday.Heading || !entering || string(node.FirstChild.Literal) == "" {
-			return blackfriday.GoToNext
-		}
+// pageName returns the name of the page of a file, files named in upper
+// case, i.e. CHANGELOG.md, are named in lower case.
+func pageName(filename string) string {
+	name := strings.TrimSuffix(filename, filepath.Ext(filename))
+	if name == strings.ToUpper(name) {
+		name = strings.ToLower(name)
+	}
 
-		if node.Level > 2 || node.HeadingID == "" {
-			if node.HeadingID != "" {
-				return blackfriday.GoToNext
-			}
+	return utils.ConvertToCamelCase(name)
+}
 
-			return blackfriday.GoToNext
-		}
+func pageFilename(page core.Page) string {
+	name := filepath.Base(page.Filepath)
+	return strings.TrimSuffix(name, filepath.Ext(name))
+}
 
-		link := fmt.Sprintf("%s#%s", page.WebPath, node.HeadingID)
-		if exists := p.uniqueLinks[link]; exists {
-			p.err = errors.Errorf("link already exists, [%s]", link)
+// readMarkdown returns the Markdown of a page, which is generated for
+// Go packages, API specs and .proto files, has IDs added to the releases
+// of a changelog and has the directives expanded for Markdown files.
+func (p *Parser) readMarkdown(page core.Page) (content []byte, err error) {
+	if p.isGoPackage(page.Filepath) {
+		if content, err = godoc_gen.BuildMarkdown(page.Filepath); err != nil {
+			err = errors.Wrapf(err, "godoc_gen.BuildMarkdown failed for [%s]", page.Filepath)
+			return
 		}
 
-		p.uniqueLinks[link] = true
-		h := core.Header{Title: string(node.FirstChild.Literal), Link: link}
-
-		if node.Level == 1 {
-			if p.serviceName == page.Name && p.serviceTitle == "" {
-				p.serviceTitle = h.Title
-			}
+		return content, nil
+	}
 
-			page.Headers = append(page.Headers, h)
+	if content, err = ioutil.ReadFile(page.Filepath); err != nil {
+		err = errors.Wrap(err, "ioutil.ReadFile failed")
+		return
+	}
 
-			return blackfriday.GoToNext
+	switch {
+	case changelog_gen.IsChangelog(page.Filepath):
+		content = changelog_gen.BuildMarkdown(content)
+	case isAPISpec(page.Filepath):
+		if content, err = openapi_gen.BuildMarkdown(content); err != nil {
+			err = errors.Wrapf(err, "openapi_gen.BuildMarkdown failed for [%s]", page.Filepath)
+			return
+		}
+	case isProtoFile(page.Filepath):
+		if content, err = proto_gen.BuildMarkdown(filepath.Base(page.Filepath), content); err != nil {
+			err = errors.Wrapf(err, "proto_gen.BuildMarkdown failed for [%s]", page.Filepath)
+			return
+		}
+	default:
+		if content, err = p.expandDirectives(page.Filepath, content, nil); err != nil {
+			err = errors.Wrap(err, "expandDirectives failed")
+			return
 		}
-
-		idx := len(page.Headers) - 1
-		page.Headers[idx].Headers = append(page.Headers[idx].Headers, h)
-
-		return blackfriday.GoToNext
 	}
+
+	return content, nil
 }
 
-func (p *Parser) searchWalker(page *core.Page) blackfriday.NodeVisitor {
-	var (
-		currentDoc    core.IndexDocument
-		uniqueIndexes = map[string]bool{}
-	)
+func (p *Parser) parseMarkdown() {
+	var renderer markdownRenderer = newGoldmarkRenderer(p.markdownConfig)
 
-	return func(node *blackfriday.Node, entering bool) blackfriday.WalkStatus {
-		if !entering {
-			if node.Type == blackfriday.Document {
-				page.IndexDocuments = append(page.IndexDocuments, currentDoc)
-			}
+	for idx, pg := range p.pages {
+		page := pg
+		zap.L().With(zap.String("page", page.Name)).Info("parsing markdown")
 
-			return blackfriday.GoToNext
+		content, err := p.readMarkdown(page)
+		if err != nil {
+			p.err = err
+			return
 		}
 
-		if node.Type == blackfriday.Heading {
-			if node.Level != 1 {
-				page.IndexDocuments = append(page.IndexDocuments, currentDoc)
-			}
-
-			var ctxIdx = node.Level - 1
-			if len(currentDoc.Context) < ctxIdx {
-				ctxIdx = len(currentDoc.Context) - 1
-			}
-
-			var context = make([]string, len(currentDoc.Context[:ctxIdx])+1)
+		fm, content, err := parseFrontMatter(content)
+		if err != nil {
+			p.err = errors.Wrapf(err, "parseFrontMatter failed for [%s]", page.Filepath)
+			return
+		}
 
-			copy(context[:ctxIdx], currentDoc.Context[:ctxIdx])
-			context[len(context)-1] = string(node.FirstChild.Literal)
+		page.Tags = fm.Tags
 
-			originalHeadingID := node.HeadingID
-			uniqueID := originalHeadingID
-			n := 1
+		if page.LastModified, err = lastModified(page.Filepath); err != nil {
+			p.err = errors.Wrapf(err, "lastModified failed for [%s]", page.Filepath)
+			return
+		}
 
-			for uniqueIndexes[uniqueID] {
-				uniqueID = fmt.Sprintf("%s-%d", originalHeadingID, n)
-				n++
+		if p.gitHistory {
+			if err = addGitHistory(&page); err != nil {
+				p.err = errors.Wrapf(err, "addGitHistory failed for [%s]", page.Filepath)
+				return
 			}
+		}
+
+		// Convert Markdown to HTML
+		rendered, err := renderer.Render(content, renderHooks{
+			resolveDestination: p.destinationResolver(page),
+			renderDiagram:      p.diagramRenderer(page),
+		})
+		if err != nil {
+			p.err = errors.Wrapf(err, "markdownRenderer.Render failed for [%s]", page.Filepath)
+			return
+		}
 
-			uniqueIndexes[uniqueID] = true
+		page.Markdown = string(rendered.HTML)
 
-			currentDoc = core.IndexDocument{}
-			currentDoc.ID = uniqueID
-			currentDoc.Link = fmt.Sprintf("%s#%s", page.WebPath, uniqueID)
-			currentDoc.Context = context
+		if rendered.MermaidDiagrams > 0 {
+			if err = p.addMermaidScript(); err != nil {
+				p.err = errors.Wrap(err, "addMermaidScript failed")
+				return
+			}
 
-			return blackfriday.GoToNext
+			p.mermaidPages[page.Name] = true
 		}
 
-		content := string(node.Literal)
-		content = strings.TrimSpace(content)
+		// Build Menu from the headings
+		if err = p.buildMenu(&page, rendered.Blocks); err != nil {
+			p.err = err
+			return
+		}
 
-		content = strings.ReplaceAll(content, "`", "` + "+`"`+"`"+`"`+" + `")
+		// Build Search Index Documents from the headings and the content
+		buildIndexDocuments(&page, rendered.Blocks)
 
-		if content != "" {
-			currentDoc.Content = append(currentDoc.Content, content)
+		if err = p.addPageMeta(&page, fm, rendered.Summary); err != nil {
+			p.err = errors.Wrapf(err, "addPageMeta failed for [%s]", page.Filepath)
+			return
 		}
 
-		return blackfriday.GoToNext
+		p.pages[idx] = page
 	}
 }
 
@@ -274,7 +388,6 @@ func (p *Parser) enrichIndexDocumentsWithHTML() {
 
 			html := page.Markdown[idx1:idx2]
 			html = strings.TrimSpace(html)
-			html = strings.ReplaceAll(html, "`", "` + "+`"`+"`"+`"`+" + `")
 
 			page.IndexDocuments[jdx].HTML = html
 		}
@@ -290,6 +403,7 @@ func (p *Parser) buildSearchPage() {
 		WithAPITitle(p.serviceTitle).
 		WithPages(p.pages).
 		WithSearchLink("/search").
+		WithSuggestLink("/suggest").
 		WithBasepath(p.basepath).
 		BuildSearchPageTemplate()
 	if err != nil {
@@ -306,20 +420,39 @@ func (p *Parser) buildHTMLPages() {
 	for idx, page := range p.pages {
 		zap.L().With(zap.String("page", page.Name)).Info("building HTML page")
 
-		bs, err := html_gen.New().
+		gen := html_gen.New().
 			WithAPITitle(p.serviceTitle).
 			WithPages(p.pages).
 			WithDocument(page.Markdown).
+			WithPageMeta(page.Title, page.Description, page.Image).
 			WithSearchLink("/search").
+			WithSuggestLink("/suggest").
 			WithBasepath(p.basepath).
 			WithFavicon(p.faviconHref).
-			Build()
+			WithFeed(p.feedTitle, p.feedHref).
+			WithCanonicalURL(p.canonicalURL(page)).
+			WithHistory(page.LastModified, page.LastModifiedBy, page.Contributors)
+
+		if p.mermaidPages[page.Name] {
+			gen = gen.WithMermaidScript(p.mermaidScriptHref)
+		}
+
+		bs, err := gen.Build()
 		if err != nil {
 			p.err = errors.Wrap(err, "html_gen.Build failed")
 			return
 		}
 
 		page.HTML = string(bs)
+
+		// The simple exporter has no suggest endpoint, so its pages are
+		// built without the suggestions.
+		if bs, err = gen.WithSuggestLink("").Build(); err != nil {
+			p.err = errors.Wrap(err, "html_gen.Build failed")
+			return
+		}
+
+		page.StaticHTML = string(bs)
 		p.pages[idx] = page
 	}
 }
@@ -0,0 +1,681 @@
+package parser_test
+
+import (
+	"encoding/json"
+	"flag"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lonnblad/go-service-doc/config"
+	"github.com/lonnblad/go-service-doc/core"
+	html_gen "github.com/lonnblad/go-service-doc/html-gen"
+	"github.com/lonnblad/go-service-doc/parser"
+	"github.com/lonnblad/go-service-doc/utils"
+)
+
+var update = flag.Bool("update", false, "update the golden files")
+
+const goldenDir = "testdata/golden"
+
+type goldenPage struct {
+	Name           string
+	WebPath        string
+	Tags           []string
+	Title          string
+	Description    string
+	Headers        []core.Header
+	IndexDocuments []goldenIndexDocument
+}
+
+type goldenIndexDocument struct {
+	ID      string
+	Link    string
+	Context []string
+	Content []string
+	Code    []string
+}
+
+// Test_Parser_Golden parses the example docs and compares the rendered
+// Markdown, the menu and the search index documents of each page with
+// the golden files, run with -update to update the golden files.
+func Test_Parser_Golden(t *testing.T) {
+	mdParser := parser.NewParser().
+		WithSourceDir("../cmd/example/docs/src").
+		WithOutputDir("generated").
+		WithBasepath("/go-service-doc").
+		ServiceFilename("bars.md")
+
+	mdParser.Run()
+	require.NoError(t, mdParser.Error())
+
+	pages := mdParser.Pages()
+	require.NotEmpty(t, pages)
+
+	for _, page := range pages {
+		page := page
+		t.Run(page.Name, func(t *testing.T) {
+			name := utils.ConvertToKebabCase(page.Name)
+
+			summary, err := json.MarshalIndent(newGoldenPage(page), "", "  ")
+			require.NoError(t, err)
+
+			assertGolden(t, name+".json", append(summary, '\n'))
+			assertGolden(t, name+".html", []byte(page.Markdown))
+		})
+	}
+}
+
+func newGoldenPage(page core.Page) goldenPage {
+	golden := goldenPage{
+		Name:        page.Name,
+		WebPath:     page.WebPath,
+		Tags:        page.Tags,
+		Title:       page.Title,
+		Description: page.Description,
+		Headers:     page.Headers,
+	}
+
+	for _, doc := range page.IndexDocuments {
+		golden.IndexDocuments = append(golden.IndexDocuments, goldenIndexDocument{
+			ID:      doc.ID,
+			Link:    doc.Link,
+			Context: doc.Context,
+			Content: doc.Content,
+			Code:    doc.Code,
+		})
+	}
+
+	return golden
+}
+
+func assertGolden(t *testing.T, filename string, actual []byte) {
+	path := filepath.Join(goldenDir, filename)
+
+	if *update {
+		require.NoError(t, ioutil.WriteFile(path, actual, utils.FilePermission))
+	}
+
+	expected, err := ioutil.ReadFile(path)
+	require.NoError(t, err)
+
+	assert.Equal(t, string(expected), string(actual))
+}
+
+func Test_Parser_Include(t *testing.T) {
+	testcases := []struct {
+		name     string
+		files    map[string]string
+		expected string
+		err      string
+	}{
+		{
+			name: "partial",
+			files: map[string]string{
+				"page.md":           "# Page {#page}\n\n{{< include \"_partials/auth.md\" >}}\n",
+				"_partials/auth.md": "Use a token.\n",
+			},
+			expected: "<p>Use a token.</p>",
+		},
+		{
+			name: "code block",
+			files: map[string]string{
+				"page.md": "```\n{{< include \"_partials/auth.md\" >}}\n```\n",
+			},
+			expected: "{{&lt; include &#34;_partials/auth.md&#34; &gt;}}",
+		},
+		{
+			name: "missing partial",
+			files: map[string]string{
+				"page.md": "# Page {#page}\n\n{{< include \"_partials/auth.md\" >}}\n",
+			},
+			err: "page.md:3: include directive failed",
+		},
+		{
+			name: "include cycle",
+			files: map[string]string{
+				"page.md":        "{{< include \"_partials/a.md\" >}}\n",
+				"_partials/a.md": "{{< include \"_partials/b.md\" >}}\n",
+				"_partials/b.md": "\n{{< include \"_partials/a.md\" >}}\n",
+			},
+			err: "_partials/b.md:2: include directive failed: include cycle",
+		},
+	}
+
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			assertParsedPage(t, tc.files, tc.expected, tc.err)
+		})
+	}
+}
+
+func Test_Parser_Snippet(t *testing.T) {
+	const source = "package main\n\nfunc main() {\n\t// docs:start hello\n\tfmt.Println(\"hello\")\n\t// docs:end\n}\n"
+
+	testcases := []struct {
+		name     string
+		page     string
+		expected string
+		err      string
+	}{
+		{
+			name:     "file",
+			page:     "{{< snippet \"main.go\" >}}\n",
+			expected: ">package</span> <span style=\"color:#a6e22e\">main</span>\n\n",
+		},
+		{
+			name:     "line range",
+			page:     "{{< snippet \"main.go\" 3-3 >}}\n",
+			expected: ">main</span>()",
+		},
+		{
+			name:     "region",
+			page:     "{{< snippet \"main.go\" hello >}}\n",
+			expected: "<pre style=\"color:#f8f8f2;background-color:#272822\"><span style=\"color:#a6e22e\">fmt</span>",
+		},
+		{
+			name: "missing region",
+			page: "# Page {#page}\n\n{{< snippet \"main.go\" goodbye >}}\n",
+			err:  "page.md:3: snippet directive failed: [main.go]: region [goodbye] not found",
+		},
+		{
+			name: "invalid line range",
+			page: "{{< snippet \"main.go\" 5-10 >}}\n",
+			err:  "invalid line range [5-10], the file has 7 lines",
+		},
+	}
+
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			files := map[string]string{"page.md": tc.page, "main.go": source}
+			assertParsedPage(t, files, tc.expected, tc.err)
+		})
+	}
+}
+
+func Test_Parser_Config(t *testing.T) {
+	files := map[string]string{
+		"page.md":          "## Configuration {#configuration}\n\n{{< config \"config\" Config >}}\n",
+		"config/config.go": "package config\n\ntype Config struct {\n\t// Port is the port to listen on.\n\tPort int `env:\"PORT\" default:\"8080\"`\n}\n",
+	}
+
+	expected := "<td><code>PORT</code></td>\n<td><code>int</code></td>\n<td><code>8080</code></td>\n<td>no</td>\n<td>Port is the port to listen on.</td>"
+
+	assertParsedPage(t, files, expected, "")
+	assertParsedPage(t, map[string]string{"page.md": "{{< config \"config\" >}}\n"}, "", "page.md:1: config directive failed")
+}
+
+func Test_Parser_Schema(t *testing.T) {
+	files := map[string]string{
+		"page.md":                 "## Bar Created {#bar-created}\n\n{{< schema \"events/bar-created.json\" >}}\n",
+		"events/bar-created.json": `{"type": "object", "properties": {"location": {"$ref": "../common/location.json"}}}`,
+		"common/location.json":    `{"type": "object", "properties": {"city": {"type": "string"}}}`,
+	}
+
+	expected := "<td><code>city</code></td>\n<td>string</td>\n<td>no</td>"
+
+	assertParsedPage(t, files, expected, "")
+
+	files["events/bar-created.json"] = `{"properties": {"bar": {"$ref": "../../bar.json"}}}`
+
+	assertParsedPage(t, files, "", "page.md:3: schema directive failed")
+}
+
+func Test_Parser_ChangelogFeed(t *testing.T) {
+	files := map[string]string{
+		"page.md":      "# Bars {#bars}\n",
+		"CHANGELOG.md": "# Changelog\n\n## [Unreleased]\n\n## [1.0.0] - 2021-01-15\n\n### Added\n\n- The bars API.\n",
+	}
+
+	testcases := []struct {
+		name    string
+		baseURL string
+		err     string
+	}{
+		{name: "feed", baseURL: "https://bars.example.com/"},
+		{name: "without a base URL", err: "requires the base URL"},
+	}
+
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			mdParser := parseFiles(t, files, func(mdParser *parser.Parser) {
+				mdParser.WithBaseURL(tc.baseURL).WithChangelog(config.Changelog{Feed: true})
+			})
+
+			if tc.err != "" {
+				require.Error(t, mdParser.Error())
+				assert.Contains(t, mdParser.Error().Error(), tc.err)
+
+				return
+			}
+
+			require.NoError(t, mdParser.Error())
+
+			feed, found := generatedFile(mdParser, "/docs/changelog.xml")
+			require.True(t, found)
+			assert.Equal(t, "application/atom+xml", feed.ContentType)
+			assert.Contains(t, string(feed.Content), "<title>Bars Changelog</title>")
+			assert.Contains(t, string(feed.Content), "<id>https://bars.example.com/docs/changelog#v1-0-0</id>")
+			assert.Contains(t, string(feed.Content), "&lt;li&gt;The bars API.&lt;/li&gt;")
+			assert.NotContains(t, string(feed.Content), "unreleased")
+
+			for _, page := range mdParser.Pages() {
+				assert.Contains(t, page.HTML, `<link rel="alternate" type="application/atom+xml" title="Bars Changelog" href="/docs/changelog.xml">`)
+			}
+		})
+	}
+}
+
+func Test_Parser_Sitemap(t *testing.T) {
+	sourceDir := writeFiles(t, map[string]string{"page.md": "# Bars {#bars}\n", "donkey-bar.md": "# Bars {#bars}\n"})
+
+	// nolint: errcheck
+	defer os.RemoveAll(sourceDir)
+
+	lastModified := time.Date(2021, 3, 1, 0, 0, 0, 0, time.UTC)
+	require.NoError(t, os.Chtimes(filepath.Join(sourceDir, "page.md"), lastModified, lastModified))
+
+	testcases := []struct {
+		name    string
+		baseURL string
+		sitemap []string
+		robots  []string
+	}{
+		{
+			name:    "base URL",
+			baseURL: "https://bars.example.com",
+			sitemap: []string{
+				"<loc>https://bars.example.com/docs</loc>\n    <lastmod>2021-03-01T00:00:00Z</lastmod>",
+				"<loc>https://bars.example.com/docs/donkey-bar</loc>",
+			},
+			robots: []string{
+				"Disallow: /docs/search\n",
+				"Sitemap: https://bars.example.com/docs/sitemap.xml\n",
+			},
+		},
+		{name: "without a base URL"},
+	}
+
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			mdParser := newParser(sourceDir).WithBaseURL(tc.baseURL)
+			mdParser.Run()
+			require.NoError(t, mdParser.Error())
+
+			for href, expected := range map[string][]string{"/docs/sitemap.xml": tc.sitemap, "/robots.txt": tc.robots} {
+				file, found := generatedFile(mdParser, href)
+				require.Equal(t, expected != nil, found, href)
+
+				for _, content := range expected {
+					assert.Contains(t, string(file.Content), content)
+				}
+			}
+
+			for _, page := range mdParser.Pages() {
+				if tc.baseURL == "" {
+					assert.NotContains(t, page.HTML, `rel="canonical"`)
+				} else {
+					assert.Contains(t, page.HTML, `<link rel="canonical" href="`+tc.baseURL+page.WebPath+`">`)
+				}
+			}
+		})
+	}
+}
+
+func Test_Parser_Suggestions(t *testing.T) {
+	mdParser := parseFiles(t, map[string]string{"page.md": "# Bars {#bars}\n"}, nil)
+	require.NoError(t, mdParser.Error())
+	require.Len(t, mdParser.Pages(), 1)
+
+	page := mdParser.Pages()[0]
+	assert.Contains(t, page.HTML, "fetch('/docs/suggest?q='")
+	assert.NotContains(t, page.StaticHTML, "/docs/suggest")
+	assert.NotContains(t, page.StaticHTML, "menu-suggestions")
+	assert.Contains(t, page.StaticHTML, `<form class=menu-search action="/docs/search"`)
+}
+
+func Test_Parser_StaticFileCollisions(t *testing.T) {
+	testcases := []struct {
+		name     string
+		files    []string
+		expected map[string]string
+		err      string
+	}{
+		{
+			name:     "unique names",
+			files:    []string{"static/a/b.png", "static/a_b.png"},
+			expected: map[string]string{"/docs/static/a/b.png": "aBPng", "/docs/static/a-b.png": "aBPng2"},
+		},
+		{
+			name:  "same href",
+			files: []string{"static/foo_bar.png", "static/foo-bar.png"},
+			err:   "have the same href, [/docs/static/foo-bar.png]",
+		},
+	}
+
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			files := map[string]string{"page.md": "# Bars {#bars}\n"}
+			for _, name := range tc.files {
+				files[name] = "# Bars {#bars}\n"
+			}
+
+			mdParser := parseFiles(t, files, nil)
+
+			if tc.err != "" {
+				require.Error(t, mdParser.Error())
+				assert.Contains(t, mdParser.Error().Error(), tc.err)
+
+				return
+			}
+
+			require.NoError(t, mdParser.Error())
+
+			names := map[string]string{}
+			for _, file := range mdParser.StaticFiles() {
+				names[file.Href] = file.Name
+			}
+
+			assert.Equal(t, tc.expected, names)
+		})
+	}
+}
+
+func Test_Parser_Mermaid(t *testing.T) {
+	const diagram = "# Bars {#bars}\n\n```mermaid\ngraph LR\n  A --> B\n```\n"
+
+	testcases := []struct {
+		name     string
+		files    map[string]string
+		expected []byte
+	}{
+		{name: "without diagrams", files: map[string]string{"page.md": "# Bars {#bars}\n"}},
+		{name: "bundled script", files: map[string]string{"page.md": diagram}, expected: html_gen.GetMermaidScript()},
+		{
+			name:     "script in the static folder",
+			files:    map[string]string{"page.md": diagram, "static/mermaid.min.js": "var mermaid;"},
+			expected: []byte("var mermaid;"),
+		},
+	}
+
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			mdParser := parseFiles(t, tc.files, nil)
+			require.NoError(t, mdParser.Error())
+
+			var script *core.File
+
+			for _, file := range mdParser.StaticFiles() {
+				if file.Href == "/docs/static/mermaid.min.js" {
+					file := file
+					script = &file
+				}
+			}
+
+			if tc.expected == nil {
+				assert.Nil(t, script)
+				assert.NotContains(t, mdParser.Pages()[0].HTML, "mermaid.initialize")
+
+				return
+			}
+
+			require.NotNil(t, script)
+			assert.Equal(t, tc.expected, script.Content)
+			assert.Contains(t, mdParser.Pages()[0].HTML, `<script src="`+script.FingerprintedHref()+`"></script>`)
+		})
+	}
+}
+
+func Test_Parser_PageMeta(t *testing.T) {
+	image, err := ioutil.ReadFile("../cmd/example/docs/src/static/favicon-16x16.png")
+	require.NoError(t, err)
+
+	files := map[string]string{
+		"page.md": "# Bars {#bars}\n\nThe bars of the city.\n",
+		"donkey-bar.md": "---\ntitle: The Donkey Bar\ndescription: A bar for \"donkeys\".\nimage: static/donkey.png\n---\n\n" +
+			"# Donkey Bar {#donkey}\n\nThe first paragraph.\n",
+		"monkey-bar.md":     "# Monkey Bar {#monkey}\n\n- A list.\n\n" + strings.Repeat("Monkeys and bananas. ", 10) + "\n",
+		"static/donkey.png": string(image),
+	}
+
+	testcases := []struct {
+		name     string
+		baseURL  string
+		expected map[string][]string
+		excluded map[string][]string
+	}{
+		{
+			name:    "base URL",
+			baseURL: "https://bars.example.com",
+			expected: map[string][]string{
+				"page": {
+					"<title>Bars</title>",
+					`<meta name="description" content="The bars of the city.">`,
+					`<meta name="twitter:card" content="summary">`,
+				},
+				"donkeyBar": {
+					"<title>The Donkey Bar – Bars</title>",
+					`<meta name="description" content="A bar for &#34;donkeys&#34;.">`,
+					`<meta property="og:title" content="The Donkey Bar">`,
+					`<meta property="og:site_name" content="Bars">`,
+					`<meta property="og:url" content="https://bars.example.com/docs/donkey-bar">`,
+					`<meta property="og:image" content="https://bars.example.com/docs/static/donkey.png">`,
+					`<meta name="twitter:card" content="summary_large_image">`,
+				},
+			},
+		},
+		{
+			// Without a base URL, the image would only have a relative URL.
+			name: "without a base URL",
+			expected: map[string][]string{
+				"donkeyBar": {
+					`<meta property="og:title" content="The Donkey Bar">`,
+					`<meta name="twitter:card" content="summary">`,
+				},
+			},
+			excluded: map[string][]string{"donkeyBar": {"og:image"}},
+		},
+	}
+
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			mdParser := parseFiles(t, files, func(mdParser *parser.Parser) {
+				mdParser.WithBaseURL(tc.baseURL)
+			})
+			require.NoError(t, mdParser.Error())
+
+			pages := map[string]core.Page{}
+			for _, page := range mdParser.Pages() {
+				pages[page.Name] = page
+			}
+
+			for name, expected := range tc.expected {
+				for _, content := range expected {
+					assert.Contains(t, pages[name].HTML, content)
+				}
+			}
+
+			for name, excluded := range tc.excluded {
+				for _, content := range excluded {
+					assert.NotContains(t, pages[name].HTML, content)
+				}
+			}
+
+			monkey := pages["monkeyBar"]
+			assert.Equal(t, "Monkey Bar", monkey.Title)
+			assert.Equal(t, strings.Repeat("Monkeys and bananas. ", 7)+"Monkeys and…", monkey.Description)
+			assert.LessOrEqual(t, utf8.RuneCountInString(monkey.Description), 160)
+		})
+	}
+}
+
+func Test_Parser_PageMeta_MissingImage(t *testing.T) {
+	files := map[string]string{"page.md": "---\nimage: static/missing.png\n---\n\n# Bars {#bars}\n"}
+
+	assertParsedPage(t, files, "", "invalid image [static/missing.png]")
+}
+
+func Test_Parser_GitHistory(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git isn't installed")
+	}
+
+	sourceDir := writeFiles(t, nil)
+
+	// nolint: errcheck
+	defer os.RemoveAll(sourceDir)
+
+	git := func(author, date string, args ...string) {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = sourceDir
+		cmd.Env = append(os.Environ(),
+			"GIT_AUTHOR_NAME="+author, "GIT_AUTHOR_EMAIL=bars@example.com", "GIT_AUTHOR_DATE="+date,
+			"GIT_COMMITTER_NAME="+author, "GIT_COMMITTER_EMAIL=bars@example.com", "GIT_COMMITTER_DATE="+date,
+		)
+
+		output, err := cmd.CombinedOutput()
+		require.NoError(t, err, string(output))
+	}
+
+	writeFile := func(name, content string) {
+		require.NoError(t, ioutil.WriteFile(filepath.Join(sourceDir, name), []byte(content), utils.FilePermission))
+	}
+
+	git("", "", "init", "--quiet")
+
+	writeFile("page.md", "# Bars {#bars}\n")
+	git("Alice", "2021-01-15T10:00:00Z", "add", "page.md")
+	git("Alice", "2021-01-15T10:00:00Z", "commit", "--quiet", "-m", "Add the bars")
+
+	writeFile("page.md", "# Bars {#bars}\n\nThe bars of the city.\n")
+	git("Bob", "2021-03-01T12:30:00Z", "commit", "--quiet", "-am", "Describe the bars")
+
+	// The history of a Go package doesn't include its sub directories.
+	require.NoError(t, os.MkdirAll(filepath.Join(sourceDir, "bars", "internal"), os.ModePerm))
+	writeFile("bars/bars.go", "// Package bars is a client for the bars.\npackage bars\n")
+	git("Alice", "2021-02-01T08:00:00Z", "add", "bars")
+	git("Alice", "2021-02-01T08:00:00Z", "commit", "--quiet", "-m", "Add the client")
+
+	writeFile("bars/internal/internal.go", "package internal\n")
+	git("Carol", "2021-04-01T08:00:00Z", "add", "bars")
+	git("Carol", "2021-04-01T08:00:00Z", "commit", "--quiet", "-m", "Add the internal package")
+
+	writeFile("donkey-bar.md", "# Donkey Bar {#donkey}\n")
+
+	mdParser := newParser(sourceDir).
+		WithBaseURL("https://bars.example.com").
+		WithGoPackages([]string{filepath.Join(sourceDir, "bars")}).
+		WithGitHistory(true)
+
+	mdParser.Run()
+	require.NoError(t, mdParser.Error())
+
+	pages := map[string]core.Page{}
+	for _, page := range mdParser.Pages() {
+		pages[page.Name] = page
+	}
+
+	page := pages["page"]
+	assert.Equal(t, time.Date(2021, 3, 1, 12, 30, 0, 0, time.UTC), page.LastModified)
+	assert.Equal(t, "Bob", page.LastModifiedBy)
+	assert.Equal(t, []string{"Bob", "Alice"}, page.Contributors)
+	assert.Contains(t, page.HTML, `Last updated on <time datetime="2021-03-01T12:30:00Z">March 1, 2021</time> by Bob.`)
+	assert.Contains(t, page.HTML, "Contributors: Bob, Alice")
+
+	sitemap, found := generatedFile(mdParser, "/docs/sitemap.xml")
+	require.True(t, found)
+	assert.Contains(t, string(sitemap.Content), "<lastmod>2021-03-01T12:30:00Z</lastmod>")
+
+	// A page that isn't committed yet keeps the modification time.
+	donkey := pages["donkeyBar"]
+	assert.False(t, donkey.LastModified.IsZero())
+	assert.Empty(t, donkey.LastModifiedBy)
+	assert.Empty(t, donkey.Contributors)
+	assert.NotContains(t, donkey.HTML, "Last updated on")
+
+	var bars core.Page
+	for _, page := range mdParser.Pages() {
+		if filepath.Base(page.Filepath) == "bars" {
+			bars = page
+		}
+	}
+
+	assert.Equal(t, time.Date(2021, 2, 1, 8, 0, 0, 0, time.UTC), bars.LastModified)
+	assert.Equal(t, []string{"Alice"}, bars.Contributors)
+}
+
+func Test_Parser_GitHistory_NotInWorkTree(t *testing.T) {
+	sourceDir := writeFiles(t, map[string]string{"page.md": "# Bars {#bars}\n"})
+
+	// nolint: errcheck
+	defer os.RemoveAll(sourceDir)
+
+	// Keeps git from finding a repository above the source directory.
+	ceiling, found := os.LookupEnv("GIT_CEILING_DIRECTORIES")
+	require.NoError(t, os.Setenv("GIT_CEILING_DIRECTORIES", filepath.Dir(sourceDir)))
+
+	// nolint: errcheck
+	defer func() {
+		if found {
+			os.Setenv("GIT_CEILING_DIRECTORIES", ceiling)
+		} else {
+			os.Unsetenv("GIT_CEILING_DIRECTORIES")
+		}
+	}()
+
+	lastModified := time.Date(2021, 3, 1, 0, 0, 0, 0, time.UTC)
+	require.NoError(t, os.Chtimes(filepath.Join(sourceDir, "page.md"), lastModified, lastModified))
+
+	mdParser := newParser(sourceDir).WithGitHistory(true)
+
+	mdParser.Run()
+	require.NoError(t, mdParser.Error())
+	require.Len(t, mdParser.Pages(), 1)
+
+	page := mdParser.Pages()[0]
+	assert.Equal(t, lastModified, page.LastModified.UTC())
+	assert.Empty(t, page.LastModifiedBy)
+}
+
+func generatedFile(mdParser *parser.Parser, href string) (core.File, bool) {
+	for _, file := range mdParser.GeneratedFiles() {
+		if file.Href == href {
+			return file, true
+		}
+	}
+
+	return core.File{}, false
+}
+
+// assertParsedPage parses the files with parseFiles, the page.md file is
+// expected to contain the expected HTML or the parsing is expected to fail
+// with an error containing expectedErr.
+func assertParsedPage(t *testing.T, files map[string]string, expected, expectedErr string) {
+	mdParser := parseFiles(t, files, nil)
+
+	if expectedErr != "" {
+		require.Error(t, mdParser.Error())
+		assert.Contains(t, mdParser.Error().Error(), expectedErr)
+
+		return
+	}
+
+	require.NoError(t, mdParser.Error())
+	require.Len(t, mdParser.Pages(), 1)
+	assert.Contains(t, mdParser.Pages()[0].Markdown, expected)
+}
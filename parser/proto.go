@@ -0,0 +1,36 @@
+package parser
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+)
+
+// findProtoFiles adds a page for each .proto file in the source directory.
+func (p *Parser) findProtoFiles() {
+	zap.L().Info("search for proto files")
+
+	files, err := ioutil.ReadDir(p.sourceDir + "/")
+	if err != nil {
+		p.err = errors.Wrap(err, "ioutil.ReadDir failed")
+		return
+	}
+
+	for _, f := range files {
+		if f.IsDir() || !isProtoFile(f.Name()) || strings.HasPrefix(f.Name(), "_") {
+			continue
+		}
+
+		if err = p.addGeneratedPage(p.sourceDir + "/" + f.Name()); err != nil {
+			p.err = err
+			return
+		}
+	}
+}
+
+func isProtoFile(path string) bool {
+	return filepath.Ext(path) == ".proto"
+}
@@ -0,0 +1,82 @@
+package parser
+
+import (
+	"net/url"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/lonnblad/go-service-doc/core"
+)
+
+// destinationResolver returns the resolveDestination hook of a page, it
+// rewrites relative destinations of images, and of links to static files,
+// to the href of the static file. The path is resolved against the
+// directory of the Markdown file and files outside of the static folder
+// are added to the static files.
+func (p *Parser) destinationResolver(page core.Page) func(destination string, image bool) (string, error) {
+	return func(rawDestination string, image bool) (_ string, err error) {
+		destination, relative := parseRelativeDestination(rawDestination)
+		if !relative {
+			return rawDestination, nil
+		}
+
+		_, staticType := staticContentTypes[strings.ToLower(filepath.Ext(destination.Path))]
+		if !image && !staticType {
+			return rawDestination, nil
+		}
+
+		if destination.Path, err = p.relativeStaticFileHref(page, destination.Path); err != nil {
+			return
+		}
+
+		return destination.String(), nil
+	}
+}
+
+func parseRelativeDestination(destination string) (_ *url.URL, relative bool) {
+	u, err := url.Parse(destination)
+	if err != nil || u.Scheme != "" || u.Host != "" || u.Path == "" || strings.HasPrefix(u.Path, "/") {
+		return nil, false
+	}
+
+	return u, true
+}
+
+// relativeStaticFileHref returns the href of the static file for a path
+// relative to the Markdown file, the file is added to the static files if
+// it isn't already.
+func (p *Parser) relativeStaticFileHref(page core.Page, relPath string) (_ string, err error) {
+	path := filepath.Join(filepath.Dir(page.Filepath), filepath.FromSlash(relPath))
+	path = filepath.Clean(path)
+
+	if href, exists := p.staticFileHrefs[path]; exists {
+		return href, nil
+	}
+
+	hrefPath, err := filepath.Rel(p.sourceDir, path)
+	if err != nil {
+		err = errors.Wrap(err, "filepath.Rel failed")
+		return
+	}
+
+	hrefPath = filepath.ToSlash(hrefPath)
+	if strings.HasPrefix(hrefPath, "../") {
+		err = errors.Errorf("relative path is outside of the source directory, [%s]", relPath)
+		return
+	}
+
+	file, supported, err := p.addStaticFile(path, hrefPath)
+	if err != nil {
+		err = errors.Wrapf(err, "failed to add static file, [%s]", relPath)
+		return
+	}
+
+	if !supported {
+		err = errors.Errorf("unsupported static file type, [%s]", relPath)
+		return
+	}
+
+	return file.Href, nil
+}
@@ -0,0 +1,94 @@
+package parser
+
+import (
+	"io/ioutil"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+
+	"github.com/lonnblad/go-service-doc/core"
+	sitemap_gen "github.com/lonnblad/go-service-doc/sitemap-gen"
+)
+
+// buildSitemap builds the sitemap of the pages and a robots.txt pointing
+// to it, if the base URL of the documentation is set. The search isn't
+// crawled, since the results only repeat the pages.
+func (p *Parser) buildSitemap() {
+	if p.baseURL == "" {
+		return
+	}
+
+	zap.L().Info("building sitemap")
+
+	sitemap, err := sitemap_gen.BuildSitemap(p.baseURL, p.pages)
+	if err != nil {
+		p.err = errors.Wrap(err, "sitemap_gen.BuildSitemap failed")
+		return
+	}
+
+	sitemapHref := p.basepath + "/" + sitemap_gen.SitemapFilename
+	robots := sitemap_gen.BuildRobots(
+		strings.TrimSuffix(p.baseURL, "/")+sitemapHref,
+		[]string{p.basepath + "/search", p.basepath + "/suggest"},
+	)
+
+	p.generatedFiles = append(p.generatedFiles,
+		core.File{
+			Name:        "sitemap",
+			Href:        sitemapHref,
+			Path:        sitemap_gen.SitemapFilename,
+			ContentType: sitemap_gen.SitemapContentType,
+			Content:     sitemap,
+		},
+		core.File{
+			Name:        "robots",
+			Href:        "/" + sitemap_gen.RobotsFilename,
+			Path:        sitemap_gen.RobotsFilename,
+			ContentType: sitemap_gen.RobotsContentType,
+			Content:     robots,
+		},
+	)
+}
+
+// canonicalURL returns the absolute URL of the page, or an empty string
+// if the base URL of the documentation isn't set.
+func (p *Parser) canonicalURL(page core.Page) string {
+	if p.baseURL == "" {
+		return ""
+	}
+
+	return strings.TrimSuffix(p.baseURL, "/") + page.WebPath
+}
+
+// lastModified returns the modification time of a file, or the latest
+// modification time of the files in a directory, i.e. a Go package.
+func lastModified(path string) (_ time.Time, err error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		err = errors.Wrap(err, "os.Stat failed")
+		return
+	}
+
+	if !info.IsDir() {
+		return info.ModTime(), nil
+	}
+
+	files, err := ioutil.ReadDir(path)
+	if err != nil {
+		err = errors.Wrap(err, "ioutil.ReadDir failed")
+		return
+	}
+
+	var latest time.Time
+
+	for _, f := range files {
+		if !f.IsDir() && f.ModTime().After(latest) {
+			latest = f.ModTime()
+		}
+	}
+
+	return latest, nil
+}
@@ -0,0 +1,177 @@
+package parser
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// snippetRegionStartRegexp and snippetRegionEndRegexp match the markers
+// of a region in a source file, i.e. // docs:start handler and
+// // docs:end.
+var (
+	snippetRegionStartRegexp = regexp.MustCompile(`^\s*//\s*docs:start\s+(\S+)\s*$`)
+	snippetRegionEndRegexp   = regexp.MustCompile(`^\s*//\s*docs:end\s*$`)
+)
+
+var snippetLinesRegexp = regexp.MustCompile(`^(\d+)(?:-(\d+))?$`)
+
+// snippet returns a fenced code block with the content of a source file.
+// The first argument is the path of the file, relative to the source
+// directory, the optional second argument is either a line range, i.e.
+// 10-20, or the name of a region in the file.
+func (p *Parser) snippet(d directive) (_ []byte, err error) {
+	if len(d.args) < 1 || len(d.args) > 2 {
+		err = errors.Errorf("expected a path and an optional line range or region, got %d arguments", len(d.args))
+		return
+	}
+
+	path := p.resolvePath(d.args[0])
+
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		err = errors.Wrap(err, "ioutil.ReadFile failed")
+		return
+	}
+
+	lines := strings.Split(strings.TrimRight(string(content), "\n"), "\n")
+
+	if len(d.args) == 2 {
+		if match := snippetLinesRegexp.FindStringSubmatch(d.args[1]); match != nil {
+			lines, err = snippetLines(lines, match[1], match[2])
+		} else {
+			lines, err = snippetRegion(lines, d.args[1])
+		}
+
+		if err != nil {
+			err = errors.Wrapf(err, "[%s]", d.args[0])
+			return
+		}
+	}
+
+	lines = removeSnippetMarkers(lines)
+	lines = dedent(lines)
+
+	code := strings.Join(lines, "\n") + "\n"
+	fence := codeFenceFor(code)
+	language := strings.TrimPrefix(filepath.Ext(path), ".")
+
+	return []byte(fence + language + "\n" + code + fence + "\n"), nil
+}
+
+// snippetLines returns the lines from start to end, both inclusive and
+// starting at 1. If end is empty, only the start line is returned.
+func snippetLines(lines []string, start, end string) (_ []string, err error) {
+	first, _ := strconv.Atoi(start)
+	last := first
+
+	if end != "" {
+		last, _ = strconv.Atoi(end)
+	}
+
+	if first < 1 || last < first || last > len(lines) {
+		err = errors.Errorf("invalid line range [%d-%d], the file has %d lines", first, last, len(lines))
+		return
+	}
+
+	return lines[first-1 : last], nil
+}
+
+// snippetRegion returns the lines between the // docs:start <name> and
+// // docs:end markers of the region.
+func snippetRegion(lines []string, name string) (_ []string, err error) {
+	start := -1
+	depth := 0
+
+	for idx, line := range lines {
+		if match := snippetRegionStartRegexp.FindStringSubmatch(line); match != nil {
+			if start >= 0 {
+				depth++
+			} else if match[1] == name {
+				start = idx + 1
+			}
+
+			continue
+		}
+
+		if start < 0 || !snippetRegionEndRegexp.MatchString(line) {
+			continue
+		}
+
+		if depth > 0 {
+			depth--
+			continue
+		}
+
+		return lines[start:idx], nil
+	}
+
+	if start >= 0 {
+		err = errors.Errorf("region [%s] is missing a docs:end marker", name)
+		return
+	}
+
+	err = errors.Errorf("region [%s] not found", name)
+
+	return
+}
+
+// removeSnippetMarkers removes the markers of the regions in a snippet.
+func removeSnippetMarkers(lines []string) []string {
+	var output []string
+
+	for _, line := range lines {
+		if snippetRegionStartRegexp.MatchString(line) || snippetRegionEndRegexp.MatchString(line) {
+			continue
+		}
+
+		output = append(output, line)
+	}
+
+	return output
+}
+
+// dedent removes the indentation that is common to all non-empty lines.
+func dedent(lines []string) []string {
+	indent, found := "", false
+
+	for _, line := range lines {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+
+		lineIndent := line[:len(line)-len(strings.TrimLeft(line, " \t"))]
+
+		if !found {
+			indent, found = lineIndent, true
+		}
+
+		for !strings.HasPrefix(lineIndent, indent) {
+			indent = indent[:len(indent)-1]
+		}
+	}
+
+	output := make([]string, len(lines))
+
+	for idx, line := range lines {
+		output[idx] = strings.TrimPrefix(line, indent)
+	}
+
+	return output
+}
+
+// codeFenceFor returns a fence that is longer than any run of backticks
+// in the code.
+func codeFenceFor(code string) string {
+	fence := "```"
+
+	for strings.Contains(code, fence) {
+		fence += "`"
+	}
+
+	return fence
+}
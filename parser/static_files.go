@@ -1,72 +1,257 @@
 package parser
 
 import (
+	"fmt"
 	"io/ioutil"
+	"net/http"
 	"os"
+	"path/filepath"
+	"regexp"
 	"strings"
+	"unicode"
 
 	"github.com/pkg/errors"
 	"go.uber.org/zap"
 
 	"github.com/lonnblad/go-service-doc/core"
+	html_gen "github.com/lonnblad/go-service-doc/html-gen"
 	"github.com/lonnblad/go-service-doc/utils"
 )
 
+// staticContentTypes contains the supported file extensions of static
+// files and their content types.
+var staticContentTypes = map[string]string{
+	".svg":   "image/svg+xml",
+	".png":   "image/png",
+	".ico":   "image/x-icon",
+	".jpg":   "image/jpeg",
+	".jpeg":  "image/jpeg",
+	".gif":   "image/gif",
+	".webp":  "image/webp",
+	".pdf":   "application/pdf",
+	".woff":  "font/woff",
+	".woff2": "font/woff2",
+	".ttf":   "font/ttf",
+	".otf":   "font/otf",
+	".eot":   "application/vnd.ms-fontobject",
+	".json":  "application/json",
+	".yaml":  "application/yaml",
+	".yml":   "application/yaml",
+	".csv":   "text/csv",
+	".js":    "text/javascript",
+	".zip":   "application/zip",
+	".tar":   "application/x-tar",
+	".gz":    "application/gzip",
+	".tgz":   "application/gzip",
+}
+
+var nonIdentifierRegexp = regexp.MustCompile(`[^a-zA-Z0-9]+`)
+
 func (p *Parser) findStaticFiles() {
 	zap.L().Info("search for static files")
 
-	files, err := ioutil.ReadDir(p.sourceDir + "/static")
-	if os.IsNotExist(err) {
+	staticDir := p.sourceDir + "/static"
+
+	if _, err := os.Stat(staticDir); os.IsNotExist(err) {
+		return
+	}
+
+	var skippedFiles []string
+
+	err := filepath.Walk(staticDir, func(path string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return err
+		}
+
+		relPath, err := filepath.Rel(staticDir, path)
+		if err != nil {
+			return errors.Wrap(err, "filepath.Rel failed")
+		}
+
+		relPath = filepath.ToSlash(relPath)
+
+		file, supported, err := p.addStaticFile(path, relPath)
+		if err != nil {
+			return err
+		}
+
+		if !supported {
+			skippedFiles = append(skippedFiles, relPath)
+			return nil
+		}
+
+		if relPath == "favicon.ico" {
+			p.faviconHref = file.FingerprintedHref()
+		}
+
+		if isMermaidScript(relPath) && p.mermaidScriptHref == "" {
+			p.mermaidScriptHref = file.FingerprintedHref()
+		}
+
+		return nil
+	})
+	if err != nil {
+		p.err = errors.Wrap(err, "filepath.Walk failed")
 		return
 	}
 
+	if len(skippedFiles) > 0 {
+		zap.L().With(zap.Strings("files", skippedFiles)).
+			Warn("skipped static files with unsupported file types")
+	}
+}
+
+// addStaticFile reads a file and adds it to the static files with the
+// href <basepath>/static/<hrefPath>, supported is false if the content
+// type of the file isn't supported.
+func (p *Parser) addStaticFile(path, hrefPath string) (file core.File, supported bool, err error) {
+	content, err := ioutil.ReadFile(path)
 	if err != nil {
-		p.err = errors.Wrap(err, "ioutil.ReadDir failed")
+		err = errors.Wrap(err, "ioutil.ReadFile failed")
 		return
 	}
 
-	for _, f := range files {
-		if f.IsDir() {
-			continue
-		}
+	file, supported, err = p.addStaticFileContent(hrefPath, content)
+	if err != nil || !supported {
+		return
+	}
+
+	p.staticFileHrefs[filepath.Clean(path)] = file.Href
+
+	return file, true, nil
+}
+
+// addStaticFileContent adds content generated during the build to the
+// static files with the href <basepath>/static/<hrefPath>. Paths that
+// only differ in what staticHrefPath converts, i.e. foo_bar.png and
+// foo-bar.png, would get the same href and fail.
+func (p *Parser) addStaticFileContent(hrefPath string, content []byte) (file core.File, supported bool, err error) {
+	file.Content = content
+
+	file.ContentType, supported = detectContentType(hrefPath, file.Content)
+	if !supported {
+		return
+	}
+
+	staticPath := staticHrefPath(hrefPath)
+
+	file.Path = p.outputDir + "/static/" + staticPath
+	file.Href = p.basepath + "/static/" + staticPath
+	file.Name = p.uniqueStaticFileName(staticFileName(staticPath))
+	file.Fingerprint = utils.Fingerprint(file.Content)
+
+	if source, exists := p.staticFileSources[file.Href]; exists {
+		err = errors.Errorf("static files [%s] and [%s] have the same href, [%s]", source, hrefPath, file.Href)
+		return
+	}
+
+	p.staticFileSources[file.Href] = hrefPath
+	p.staticFiles = append(p.staticFiles, file)
+
+	return file, true, nil
+}
+
+// uniqueStaticFileName adds a numeric suffix to names already used by
+// another static file, i.e. a/b.png and a_b.png will be aBPng and aBPng2.
+func (p *Parser) uniqueStaticFileName(name string) string {
+	unique := name
+
+	for idx := 2; p.staticFileNames[unique]; idx++ {
+		unique = fmt.Sprintf("%s%d", name, idx)
+	}
+
+	p.staticFileNames[unique] = true
+
+	return unique
+}
+
+// detectContentType detects the content type by the file extension and
+// falls back to sniffing the content for files without a known extension.
+func detectContentType(filename string, content []byte) (contentType string, supported bool) {
+	if contentType, supported = staticContentTypes[strings.ToLower(filepath.Ext(filename))]; supported {
+		return
+	}
+
+	contentType = http.DetectContentType(content)
+	mediaType := strings.TrimSpace(strings.Split(contentType, ";")[0])
 
-		var (
-			file          core.File
-			fileExtension string
-		)
-
-		switch {
-		case strings.HasSuffix(f.Name(), ".svg"):
-			file.ContentType = "image/svg+xml"
-			fileExtension = ".svg"
-		case strings.HasSuffix(f.Name(), ".png"):
-			file.ContentType = "image/png"
-			fileExtension = ".png"
-		case strings.HasSuffix(f.Name(), ".ico"):
-			file.ContentType = "image/ico"
-			fileExtension = ".ico"
-		default:
-			continue
+	for _, supportedType := range staticContentTypes {
+		if mediaType == supportedType {
+			return mediaType, true
 		}
+	}
+
+	return "", false
+}
+
+// staticHrefPath converts each part of the path to kebab-case.
+func staticHrefPath(relPath string) string {
+	parts := strings.Split(relPath, "/")
+	for idx, part := range parts {
+		parts[idx] = utils.ConvertToKebabCase(part)
+	}
 
-		file.Path = p.outputDir + "/static/" + f.Name()
-		file.Href = p.basepath + "/static/" + utils.ConvertToKebabCase(f.Name())
+	return strings.Join(parts, "/")
+}
+
+// staticFileName converts the path to a camelCase name usable as an
+// identifier in go, i.e. img/diagram.png will be imgDiagramPng.
+func staticFileName(relPath string) string {
+	name := nonIdentifierRegexp.ReplaceAllString(relPath, "_")
+	name = strings.Trim(name, "_")
+	name = utils.ConvertToCamelCase(name)
+
+	if name == "" || !unicode.IsLetter(rune(name[0])) {
+		name = "file" + strings.Title(name)
+	}
+
+	return name
+}
+
+// fingerprintStaticFileReferences rewrites the references to static files
+// in the rendered Markdown of the pages, page.Markdown, to the
+// fingerprinted hrefs. A reference is a quoted href, which can be followed
+// by a fragment or a query.
+func (p *Parser) fingerprintStaticFileReferences() {
+	var oldnew []string
 
-		if f.Name() == "favicon.ico" {
-			p.faviconHref = file.Href
+	for _, file := range p.staticFiles {
+		for _, suffix := range []string{`"`, `#`, `?`} {
+			oldnew = append(oldnew, `"`+file.Href+suffix, `"`+file.FingerprintedHref()+suffix)
 		}
+	}
 
-		file.Name = strings.ReplaceAll(f.Name(), fileExtension, "")
-		file.Name = utils.ConvertToCamelCase(file.Name)
+	replacer := strings.NewReplacer(oldnew...)
 
-		filepath := p.sourceDir + "/static/" + f.Name()
+	for idx, page := range p.pages {
+		page.Markdown = replacer.Replace(page.Markdown)
+		p.pages[idx] = page
+	}
+}
 
-		file.Content, err = ioutil.ReadFile(filepath)
-		if err != nil {
-			p.err = errors.Wrap(err, "ioutil.ReadFile failed")
-			return
+func isMermaidScript(relPath string) bool {
+	for _, scriptPath := range mermaidScriptPaths {
+		if relPath == scriptPath {
+			return true
 		}
+	}
+
+	return false
+}
+
+// addMermaidScript adds the bundled Mermaid script to the static files,
+// unless a Mermaid script was found in the static folder.
+func (p *Parser) addMermaidScript() (err error) {
+	if p.mermaidScriptHref != "" {
+		return nil
+	}
 
-		p.staticFiles = append(p.staticFiles, file)
+	file, _, err := p.addStaticFileContent(html_gen.MermaidScriptFilename, html_gen.GetMermaidScript())
+	if err != nil {
+		return
 	}
+
+	p.mermaidScriptHref = file.FingerprintedHref()
+
+	return nil
 }
@@ -0,0 +1,45 @@
+package parser_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lonnblad/go-service-doc/utils"
+)
+
+func Test_Parser_FingerprintStaticFileReferences(t *testing.T) {
+	const svg = "<svg></svg>"
+
+	href := utils.AddFingerprint("/docs/static/bars.svg", utils.Fingerprint([]byte(svg)))
+
+	testcases := []struct {
+		name     string
+		markdown string
+		expected string
+	}{
+		{name: "image", markdown: "![Bars](static/bars.svg)", expected: `<img src="` + href + `" alt="Bars">`},
+		{name: "href", markdown: `<a href="/docs/static/bars.svg">Bars</a>`, expected: `<a href="` + href + `">Bars</a>`},
+		{name: "fragment", markdown: `<a href="/docs/static/bars.svg#icon">Bars</a>`, expected: `<a href="` + href + `#icon">Bars</a>`},
+		{name: "query", markdown: `<img src="/docs/static/bars.svg?v=1">`, expected: `<img src="` + href + `?v=1">`},
+		{name: "other file", markdown: `<a href="/docs/static/bars.svgz">Bars</a>`, expected: `<a href="/docs/static/bars.svgz">Bars</a>`},
+		{name: "not static", markdown: `<a href="/docs/bars.svg">Bars</a>`, expected: `<a href="/docs/bars.svg">Bars</a>`},
+	}
+
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			files := map[string]string{
+				"page.md":         "# Bars {#bars}\n\n" + tc.markdown + "\n",
+				"static/bars.svg": svg,
+			}
+
+			mdParser := parseFiles(t, files, nil)
+			require.NoError(t, mdParser.Error())
+			require.Len(t, mdParser.Pages(), 1)
+
+			assert.Contains(t, mdParser.Pages()[0].Markdown, tc.expected)
+		})
+	}
+}
@@ -0,0 +1,325 @@
+package gen
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/emicklei/proto"
+	"github.com/pkg/errors"
+
+	"github.com/lonnblad/go-service-doc/utils"
+)
+
+// BuildMarkdown generates the Markdown of a documentation page from a
+// .proto file, with a section per service and sections with the messages
+// and enums. Messages and enums used as types are linked to their section.
+func BuildMarkdown(filename string, content []byte) (_ []byte, err error) {
+	parser := proto.NewParser(bytes.NewReader(content))
+	parser.Filename(filename)
+
+	definition, err := parser.Parse()
+	if err != nil {
+		err = errors.Wrap(err, "proto.Parser.Parse failed")
+		return
+	}
+
+	md := &markdown{types: map[string]string{}}
+	md.collect(definition.Elements, "")
+
+	md.writeFile(filename)
+
+	for _, srv := range md.services {
+		md.writeService(srv)
+	}
+
+	if len(md.messages) > 0 {
+		md.line("## Messages {#messages}\n")
+
+		for _, msg := range md.messages {
+			md.writeMessage(msg)
+		}
+	}
+
+	if len(md.enums) > 0 {
+		md.line("## Enums {#enums}\n")
+
+		for _, enum := range md.enums {
+			md.writeEnum(enum)
+		}
+	}
+
+	return []byte(md.String()), nil
+}
+
+type markdown struct {
+	strings.Builder
+
+	pkg      string
+	syntax   string
+	comment  *proto.Comment
+	imports  []string
+	services []*proto.Service
+	messages []namedMessage
+	enums    []namedEnum
+
+	// types maps the full names of the messages and enums, without the
+	// package, to the IDs of their sections.
+	types map[string]string
+}
+
+type namedMessage struct {
+	name    string
+	message *proto.Message
+}
+
+type namedEnum struct {
+	name string
+	enum *proto.Enum
+}
+
+func (md *markdown) line(format string, args ...interface{}) {
+	fmt.Fprintf(md, format+"\n", args...)
+}
+
+// collect collects the definitions of the file, nested messages and enums
+// are named with the names of the messages they are nested in, i.e.
+// Bar.Location.
+func (md *markdown) collect(elements []proto.Visitee, prefix string) {
+	for _, element := range elements {
+		switch e := element.(type) {
+		case *proto.Syntax:
+			md.syntax = e.Value
+			md.comment = e.Comment
+		case *proto.Package:
+			md.pkg = e.Name
+
+			if md.comment == nil {
+				md.comment = e.Comment
+			}
+		case *proto.Import:
+			md.imports = append(md.imports, e.Filename)
+		case *proto.Service:
+			md.services = append(md.services, e)
+		case *proto.Message:
+			if e.IsExtend {
+				continue
+			}
+
+			name := prefix + e.Name
+			md.messages = append(md.messages, namedMessage{name: name, message: e})
+			md.types[name] = "message-" + slug(name)
+			md.collect(e.Elements, name+".")
+		case *proto.Enum:
+			name := prefix + e.Name
+			md.enums = append(md.enums, namedEnum{name: name, enum: e})
+			md.types[name] = "enum-" + slug(name)
+		}
+	}
+}
+
+func (md *markdown) writeFile(filename string) {
+	title := md.pkg
+	if title == "" {
+		title = filename
+	}
+
+	md.line("# %s {#%s}\n", title, utils.Slug(title))
+	md.writeComment(md.comment)
+
+	if md.syntax != "" {
+		md.line("Syntax: `%s`\n", md.syntax)
+	}
+
+	if len(md.imports) == 0 {
+		return
+	}
+
+	md.line("Imports:\n")
+
+	for _, imp := range md.imports {
+		md.line("- `%s`", imp)
+	}
+
+	md.line("")
+}
+
+func (md *markdown) writeService(srv *proto.Service) {
+	id := "service-" + slug(srv.Name)
+
+	md.line("## %s {#%s}\n", srv.Name, id)
+	md.writeComment(srv.Comment)
+
+	for _, element := range srv.Elements {
+		rpc, ok := element.(*proto.RPC)
+		if !ok {
+			continue
+		}
+
+		md.line("### %s {#%s-%s}\n", rpc.Name, id, slug(rpc.Name))
+		md.writeComment(rpc.Comment, rpc.InlineComment)
+
+		md.line("| Request | Response |")
+		md.line("| ------- | -------- |")
+		md.line("| %s | %s |\n",
+			md.streamType(rpc.RequestType, rpc.StreamsRequest),
+			md.streamType(rpc.ReturnsType, rpc.StreamsReturns),
+		)
+	}
+}
+
+func (md *markdown) writeMessage(msg namedMessage) {
+	md.line("### %s {#%s}\n", msg.name, md.types[msg.name])
+	md.writeComment(msg.message.Comment)
+
+	var rows []string
+
+	for _, element := range msg.message.Elements {
+		rows = append(rows, md.fieldRows(element, msg.name, "")...)
+	}
+
+	if len(rows) == 0 {
+		return
+	}
+
+	md.line("| Field | Number | Type | Label | Description |")
+	md.line("| ----- | ------ | ---- | ----- | ----------- |")
+
+	for _, row := range rows {
+		md.line("%s", row)
+	}
+
+	md.line("")
+}
+
+// fieldRows returns the table rows of a field of a message, or of the
+// fields of a oneof.
+func (md *markdown) fieldRows(element proto.Visitee, scope, label string) []string {
+	switch e := element.(type) {
+	case *proto.NormalField:
+		switch {
+		case e.Repeated:
+			label = "repeated"
+		case e.Optional:
+			label = "optional"
+		case e.Required:
+			label = "required"
+		}
+
+		return []string{md.fieldRow(e.Field, md.typeLink(e.Type, scope), label)}
+	case *proto.MapField:
+		typ := fmt.Sprintf("`map<%s, %s>`", e.KeyType, e.Type)
+		if link := md.typeLink(e.Type, scope); !strings.HasPrefix(link, "`") {
+			typ = fmt.Sprintf("map\\<%s, %s\\>", e.KeyType, link)
+		}
+
+		return []string{md.fieldRow(e.Field, typ, label)}
+	case *proto.OneOfField:
+		return []string{md.fieldRow(e.Field, md.typeLink(e.Type, scope), label)}
+	case *proto.Oneof:
+		var rows []string
+
+		for _, field := range e.Elements {
+			rows = append(rows, md.fieldRows(field, scope, "oneof "+e.Name)...)
+		}
+
+		return rows
+	}
+
+	return nil
+}
+
+func (md *markdown) fieldRow(field *proto.Field, typ, label string) string {
+	return fmt.Sprintf("| `%s` | %d | %s | %s | %s |",
+		field.Name, field.Sequence, typ, label, utils.EscapeTableCell(commentText(field.Comment, field.InlineComment)))
+}
+
+func (md *markdown) writeEnum(enum namedEnum) {
+	md.line("### %s {#%s}\n", enum.name, md.types[enum.name])
+	md.writeComment(enum.enum.Comment)
+
+	md.line("| Name | Number | Description |")
+	md.line("| ---- | ------ | ----------- |")
+
+	for _, element := range enum.enum.Elements {
+		if value, ok := element.(*proto.EnumField); ok {
+			md.line("| `%s` | %d | %s |",
+				value.Name, value.Integer, utils.EscapeTableCell(commentText(value.Comment, value.InlineComment)))
+		}
+	}
+
+	md.line("")
+}
+
+func (md *markdown) streamType(typ string, stream bool) string {
+	link := md.typeLink(typ, "")
+	if stream {
+		return "stream " + link
+	}
+
+	return link
+}
+
+// typeLink returns the type, linked to its section if it's a message or
+// an enum of the file. Relative types are resolved from the innermost
+// scope, like protoc does.
+func (md *markdown) typeLink(typ, scope string) string {
+	name := strings.TrimPrefix(typ, ".")
+	if md.pkg != "" {
+		name = strings.TrimPrefix(name, md.pkg+".")
+	}
+
+	for {
+		candidate := name
+		if scope != "" {
+			candidate = scope + "." + name
+		}
+
+		if id, exists := md.types[candidate]; exists {
+			return fmt.Sprintf("[%s](#%s)", typ, id)
+		}
+
+		if scope == "" {
+			return "`" + typ + "`"
+		}
+
+		if idx := strings.LastIndex(scope, "."); idx >= 0 {
+			scope = scope[:idx]
+		} else {
+			scope = ""
+		}
+	}
+}
+
+// slug returns the ID of a section of a definition, i.e. bar-location
+// for Bar.Location.
+func slug(name string) string {
+	return utils.Slug(utils.ConvertToKebabCase(name))
+}
+
+func (md *markdown) writeComment(comments ...*proto.Comment) {
+	if text := commentText(comments...); text != "" {
+		md.line("%s\n", text)
+	}
+}
+
+// commentText returns the text of the first comment that isn't empty,
+// with the comment markers and the indentation removed.
+func commentText(comments ...*proto.Comment) string {
+	for _, comment := range comments {
+		if comment == nil {
+			continue
+		}
+
+		lines := make([]string, len(comment.Lines))
+		for idx, line := range comment.Lines {
+			lines[idx] = strings.TrimSpace(strings.TrimPrefix(line, "/"))
+		}
+
+		if text := strings.TrimSpace(strings.Join(lines, "\n")); text != "" {
+			return text
+		}
+	}
+
+	return ""
+}
@@ -0,0 +1,71 @@
+package gen_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	proto_gen "github.com/lonnblad/go-service-doc/proto-gen"
+)
+
+const protoFile = `
+syntax = "proto3";
+
+package bars.v1;
+
+// BarService manages the bars.
+service BarService {
+  // Streams the bars.
+  rpc WatchBars(WatchBarsRequest) returns (stream Bar);
+}
+
+message WatchBarsRequest {
+  Bar.Kind kind = 1; // Only watch bars of the kind.
+}
+
+message Bar {
+  enum Kind {
+    KIND_UNSPECIFIED = 0;
+    // Bars for monkeys.
+    KIND_MONKEY = 1;
+  }
+
+  message Location {
+    double latitude = 1;
+  }
+
+  Kind kind = 1;
+  .bars.v1.Bar.Location location = 2;
+  map<string, Location> entrances = 3;
+  oneof owner {
+    string user_id = 4;
+  }
+}
+`
+
+func Test_BuildMarkdown(t *testing.T) {
+	md, err := proto_gen.BuildMarkdown("bars.proto", []byte(protoFile))
+	require.NoError(t, err)
+
+	for _, expected := range []string{
+		"# bars.v1 {#bars-v1}",
+		"## BarService {#service-bar-service}\n\nBarService manages the bars.",
+		"### WatchBars {#service-bar-service-watch-bars}\n\nStreams the bars.",
+		"| [WatchBarsRequest](#message-watch-bars-request) | stream [Bar](#message-bar) |",
+		"| `kind` | 1 | [Bar.Kind](#enum-bar-kind) |  | Only watch bars of the kind. |",
+		"| `kind` | 1 | [Kind](#enum-bar-kind) |  |  |",
+		"| `location` | 2 | [.bars.v1.Bar.Location](#message-bar-location) |  |  |",
+		"| `entrances` | 3 | map\\<string, [Location](#message-bar-location)\\> |  |  |",
+		"| `user_id` | 4 | `string` | oneof owner |  |",
+		"### Bar.Location {#message-bar-location}",
+		"| `KIND_MONKEY` | 1 | Bars for monkeys. |",
+	} {
+		assert.Contains(t, string(md), expected)
+	}
+}
+
+func Test_BuildMarkdown_InvalidFile(t *testing.T) {
+	_, err := proto_gen.BuildMarkdown("bars.proto", []byte("message Bar {"))
+	assert.Error(t, err)
+}
@@ -0,0 +1,224 @@
+package gen
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/blevesearch/bleve"
+	"github.com/blevesearch/bleve/analysis/analyzer/custom"
+	"github.com/blevesearch/bleve/analysis/analyzer/keyword"
+	"github.com/blevesearch/bleve/analysis/token/camelcase"
+	"github.com/blevesearch/bleve/analysis/token/lowercase"
+	"github.com/blevesearch/bleve/analysis/tokenizer/regexp"
+	"github.com/blevesearch/bleve/index/store/gtreap"
+	"github.com/blevesearch/bleve/index/upsidedown"
+	"github.com/blevesearch/bleve/mapping"
+	"github.com/pkg/errors"
+
+	"github.com/lonnblad/go-service-doc/core"
+	"github.com/lonnblad/go-service-doc/utils"
+)
+
+type Gen struct {
+	pages    core.Pages
+	language string
+}
+
+func New() *Gen {
+	return &Gen{language: DefaultLanguage}
+}
+
+func (g *Gen) WithPages(pages core.Pages) *Gen {
+	g.pages = pages
+	return g
+}
+
+// WithLanguage sets the language used to analyze the documents and the
+// search queries, i.e. "en" for English stemming.
+func (g *Gen) WithLanguage(language string) *Gen {
+	g.language = language
+	return g
+}
+
+// Index is a prebuilt search index, the rows of the index, encoded and
+// gzipped, and the mapping, as JSON, that the index is opened with.
+type Index struct {
+	Mapping []byte
+	Rows    []byte
+}
+
+// Build indexes the documents of all pages in an in-memory bleve index
+// and returns the rows of the index. The rows are sorted by key, so the
+// same pages always give the same index.
+func (g *Gen) Build() (_ Index, err error) {
+	if !languages[g.language] {
+		err = errors.Errorf("unsupported search language, [%s]", g.language)
+		return
+	}
+
+	indexMapping, err := newIndexMapping(g.language)
+	if err != nil {
+		return
+	}
+
+	searchIndex, err := bleve.NewUsing("", indexMapping, upsidedown.Name, gtreap.Name, nil)
+	if err != nil {
+		err = errors.Wrap(err, "bleve.NewUsing failed")
+		return
+	}
+
+	// nolint: errcheck
+	defer searchIndex.Close()
+
+	if err = g.indexDocuments(searchIndex); err != nil {
+		return
+	}
+
+	mappingJSON, err := json.Marshal(indexMapping)
+	if err != nil {
+		err = errors.Wrap(err, "json.Marshal failed")
+		return
+	}
+
+	rows, err := dumpRows(searchIndex)
+	if err != nil {
+		return
+	}
+
+	return Index{Mapping: mappingJSON, Rows: rows}, nil
+}
+
+// indexDocuments indexes the documents one at a time, since the fields
+// of the index are numbered in the order they are first indexed.
+func (g *Gen) indexDocuments(searchIndex bleve.Index) (err error) {
+	for _, page := range g.pages {
+		pageName := utils.ConvertToKebabCase(page.Name)
+
+		tags := make([]string, len(page.Tags))
+		for idx, tag := range page.Tags {
+			tags[idx] = strings.ToLower(tag)
+		}
+
+		for _, indexDoc := range page.IndexDocuments {
+			doc := document{
+				Link:    indexDoc.Link,
+				Page:    pageName,
+				Tags:    tags,
+				Context: indexDoc.Context,
+				Content: indexDoc.Content,
+				Code:    indexDoc.Code,
+				HTML:    indexDoc.HTML,
+			}
+
+			if err = searchIndex.Index(doc.Link, doc); err != nil {
+				return errors.Wrapf(err, "searchIndex.Index failed for [%s]", doc.Link)
+			}
+		}
+	}
+
+	return nil
+}
+
+const (
+	codeAnalyzer      = "code"
+	codePartsAnalyzer = "code_parts"
+)
+
+// newIndexMapping creates a mapping where the headings of a document are
+// indexed in Context and the text in Content, both analyzed with the
+// language analyzer. Code is indexed as whole identifiers in Code and
+// split on camel case and underscores in CodeParts. Page and Tags are
+// indexed as keywords to be used as filters and facets. Link and HTML
+// are only stored to build the result. The composite _all field is
+// disabled.
+func newIndexMapping(language string) (_ mapping.IndexMapping, err error) {
+	contextField := bleve.NewTextFieldMapping()
+	contextField.Analyzer = language
+
+	contentField := bleve.NewTextFieldMapping()
+	contentField.Analyzer = language
+	contentField.Store = false
+
+	keywordField := bleve.NewTextFieldMapping()
+	keywordField.Analyzer = keyword.Name
+	keywordField.Store = false
+	keywordField.IncludeInAll = false
+
+	codeField := bleve.NewTextFieldMapping()
+	codeField.Analyzer = codeAnalyzer
+	codeField.Store = false
+
+	codePartsField := bleve.NewTextFieldMapping()
+	codePartsField.Name = "CodeParts"
+	codePartsField.Analyzer = codePartsAnalyzer
+	codePartsField.Store = false
+
+	storedField := bleve.NewTextFieldMapping()
+	storedField.Index = false
+	storedField.IncludeInAll = false
+	storedField.IncludeTermVectors = false
+
+	docMapping := bleve.NewDocumentStaticMapping()
+	docMapping.AddFieldMappingsAt("Context", contextField)
+	docMapping.AddFieldMappingsAt("Content", contentField)
+	docMapping.AddFieldMappingsAt("Code", codeField, codePartsField)
+	docMapping.AddFieldMappingsAt("Page", keywordField)
+	docMapping.AddFieldMappingsAt("Tags", keywordField)
+	docMapping.AddFieldMappingsAt("Link", storedField)
+	docMapping.AddFieldMappingsAt("HTML", storedField)
+
+	// The queries always set the field, so the composite _all field isn't
+	// needed, and bleve merges its term vectors in the order of a map, which
+	// would give different rows for the same pages.
+	docMapping.AddSubDocumentMapping("_all", bleve.NewDocumentDisabledMapping())
+
+	indexMapping := bleve.NewIndexMapping()
+	indexMapping.DefaultAnalyzer = language
+	indexMapping.DefaultMapping = docMapping
+
+	if err = addCodeAnalyzers(indexMapping); err != nil {
+		return
+	}
+
+	return indexMapping, nil
+}
+
+// addCodeAnalyzers adds analyzers for code where identifiers like
+// user_id and ConvertToKebabCase are kept as tokens by the code analyzer
+// and split into user, id, convert, to, kebab and case by the code parts
+// analyzer.
+func addCodeAnalyzers(indexMapping *mapping.IndexMappingImpl) (err error) {
+	tokenizers := map[string]string{
+		codeAnalyzer:      `[\p{L}\p{N}_]+`,
+		codePartsAnalyzer: `[\p{L}\p{N}]+`,
+	}
+
+	tokenFilters := map[string][]string{
+		codeAnalyzer:      {lowercase.Name},
+		codePartsAnalyzer: {camelcase.Name, lowercase.Name},
+	}
+
+	for name, pattern := range tokenizers {
+		tokenizer := map[string]interface{}{"type": regexp.Name, "regexp": pattern}
+		if err = indexMapping.AddCustomTokenizer(name, tokenizer); err != nil {
+			return errors.Wrap(err, "indexMapping.AddCustomTokenizer failed")
+		}
+
+		analyzer := map[string]interface{}{"type": custom.Name, "tokenizer": name, "token_filters": tokenFilters[name]}
+		if err = indexMapping.AddCustomAnalyzer(name, analyzer); err != nil {
+			return errors.Wrap(err, "indexMapping.AddCustomAnalyzer failed")
+		}
+	}
+
+	return nil
+}
+
+type document struct {
+	Link    string
+	Page    string
+	Tags    []string
+	Context []string
+	Content []string
+	Code    []string
+	HTML    string
+}
@@ -0,0 +1,217 @@
+package gen_test
+
+import (
+	"testing"
+
+	"github.com/blevesearch/bleve"
+	"github.com/blevesearch/bleve/search/query"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lonnblad/go-service-doc/core"
+	search_gen "github.com/lonnblad/go-service-doc/search-gen"
+)
+
+var pages = core.Pages{
+	{
+		Name: "donkeyBar",
+		Tags: []string{"Code"},
+		IndexDocuments: []core.IndexDocument{
+			{
+				Link:    "/docs/donkey-bar#donkey",
+				Context: []string{"Bars", "Donkey Bar"},
+				Content: []string{"The donkeys drink at the bar."},
+				HTML:    "<p>The donkeys drink at the bar.</p>",
+			},
+			{
+				Link:    "/docs/donkey-bar#handler",
+				Context: []string{"Bars", "Donkey Bar", "Handler"},
+				Code:    []string{"func ServeHTTP(w http.ResponseWriter, req *http.Request)", "user_id"},
+				HTML:    "<pre>func ServeHTTP</pre>",
+			},
+		},
+	},
+	{
+		Name: "monkeyBar",
+		Tags: []string{"lists"},
+		IndexDocuments: []core.IndexDocument{
+			{
+				Link:    "/docs/monkey-bar#monkey",
+				Context: []string{"Bars", "Monkey Bar"},
+				Content: []string{"Monkeys eat bananas."},
+				HTML:    "<p>Monkeys eat bananas.</p>",
+			},
+		},
+	},
+}
+
+func Test_Build(t *testing.T) {
+	searchIndex, err := search_gen.New().WithPages(pages).Build()
+	require.NoError(t, err)
+
+	index, err := search_gen.Open(searchIndex)
+	require.NoError(t, err)
+
+	defer index.Close()
+
+	count, err := index.DocCount()
+	require.NoError(t, err)
+	assert.Equal(t, uint64(3), count)
+
+	testcases := []struct {
+		name     string
+		field    string
+		analyzer string
+		text     string
+		expected []string
+	}{
+		{name: "context", field: "Context", text: "monkey", expected: []string{"/docs/monkey-bar#monkey"}},
+		{name: "content", field: "Content", text: "bananas", expected: []string{"/docs/monkey-bar#monkey"}},
+		{name: "code identifier", field: "Code", text: "user_id", expected: []string{"/docs/donkey-bar#handler"}},
+		{name: "code part", field: "CodeParts", analyzer: "code_parts", text: "id", expected: []string{"/docs/donkey-bar#handler"}},
+		{name: "code part not in code", field: "Code", text: "id"},
+		{name: "stemmed context", field: "Context", text: "handlers", expected: []string{"/docs/donkey-bar#handler"}},
+		{name: "stemmed content", field: "Content", text: "bars", expected: []string{"/docs/donkey-bar#donkey"}},
+		{name: "code identifier in camel case", field: "Code", text: "ServeHTTP", expected: []string{"/docs/donkey-bar#handler"}},
+		{name: "code part of camel case", field: "CodeParts", analyzer: "code_parts", text: "serve", expected: []string{"/docs/donkey-bar#handler"}},
+		{name: "code parts of camel case", field: "CodeParts", analyzer: "code_parts", text: "ResponseWriter", expected: []string{"/docs/donkey-bar#handler"}},
+	}
+
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			matchQuery := bleve.NewMatchQuery(tc.text)
+			matchQuery.SetField(tc.field)
+			matchQuery.Analyzer = tc.analyzer
+
+			assert.Equal(t, tc.expected, search(t, index, matchQuery))
+		})
+	}
+
+	t.Run("filters", func(t *testing.T) {
+		pageQuery := bleve.NewTermQuery("donkey-bar")
+		pageQuery.SetField("Page")
+
+		tagQuery := bleve.NewTermQuery("code")
+		tagQuery.SetField("Tags")
+
+		expected := []string{"/docs/donkey-bar#donkey", "/docs/donkey-bar#handler"}
+		assert.Equal(t, expected, search(t, index, pageQuery))
+		assert.Equal(t, expected, search(t, index, tagQuery))
+	})
+
+	// The facets are built from the back index rows, which are sorted by
+	// search-gen, so this round-trips the format of the rows.
+	t.Run("facets", func(t *testing.T) {
+		request := bleve.NewSearchRequest(bleve.NewMatchAllQuery())
+		request.AddFacet("Page", bleve.NewFacetRequest("Page", 10))
+		request.AddFacet("Tags", bleve.NewFacetRequest("Tags", 10))
+
+		result, err := index.Search(request)
+		require.NoError(t, err)
+
+		facetTerms := func(name string) map[string]int {
+			terms := map[string]int{}
+			for _, term := range result.Facets[name].Terms {
+				terms[term.Term] = term.Count
+			}
+
+			return terms
+		}
+
+		assert.Equal(t, map[string]int{"donkey-bar": 2, "monkey-bar": 1}, facetTerms("Page"))
+		assert.Equal(t, map[string]int{"code": 2, "lists": 1}, facetTerms("Tags"))
+	})
+
+	t.Run("stored fields", func(t *testing.T) {
+		request := bleve.NewSearchRequest(bleve.NewDocIDQuery([]string{"/docs/monkey-bar#monkey"}))
+		request.Fields = []string{"Context", "HTML", "Link"}
+
+		result, err := index.Search(request)
+		require.NoError(t, err)
+		require.Len(t, result.Hits, 1)
+
+		assert.Equal(t, []interface{}{"Bars", "Monkey Bar"}, result.Hits[0].Fields["Context"])
+		assert.Equal(t, "<p>Monkeys eat bananas.</p>", result.Hits[0].Fields["HTML"])
+		assert.Equal(t, "/docs/monkey-bar#monkey", result.Hits[0].Fields["Link"])
+	})
+}
+
+func Test_Build_Deterministic(t *testing.T) {
+	first, err := search_gen.New().WithPages(pages).Build()
+	require.NoError(t, err)
+
+	for i := 0; i < 3; i++ {
+		searchIndex, err := search_gen.New().WithPages(pages).Build()
+		require.NoError(t, err)
+
+		assert.Equal(t, first, searchIndex)
+	}
+}
+
+func Test_Build_Language(t *testing.T) {
+	searchIndex, err := search_gen.New().WithPages(pages).WithLanguage("de").Build()
+	require.NoError(t, err)
+
+	index, err := search_gen.Open(searchIndex)
+	require.NoError(t, err)
+
+	defer index.Close()
+
+	// The German analyzer doesn't stem the English plural.
+	matchQuery := bleve.NewMatchQuery("bars")
+	matchQuery.SetField("Content")
+
+	assert.Empty(t, search(t, index, matchQuery))
+}
+
+func Test_Build_UnknownLanguage(t *testing.T) {
+	_, err := search_gen.New().WithPages(pages).WithLanguage("xx").Build()
+	assert.EqualError(t, err, "unsupported search language, [xx]")
+}
+
+func Test_Open_InvalidRows(t *testing.T) {
+	searchIndex, err := search_gen.New().WithPages(pages).Build()
+	require.NoError(t, err)
+
+	searchIndex.Rows = searchIndex.Rows[:len(searchIndex.Rows)/2]
+
+	_, err = search_gen.Open(searchIndex)
+	assert.Error(t, err)
+}
+
+func Test_Open_ReadOnly(t *testing.T) {
+	searchIndex, err := search_gen.New().WithPages(pages).Build()
+	require.NoError(t, err)
+
+	index, err := search_gen.Open(searchIndex)
+	require.NoError(t, err)
+
+	defer index.Close()
+
+	err = index.Index("/docs/donkey-bar#new", map[string]interface{}{"Content": "new"})
+	assert.Error(t, err)
+
+	err = index.Delete("/docs/monkey-bar#monkey")
+	assert.Error(t, err)
+
+	count, err := index.DocCount()
+	require.NoError(t, err)
+	assert.Equal(t, uint64(3), count)
+}
+
+// search returns the links of the documents matching the query, sorted
+// by link.
+func search(t *testing.T, index bleve.Index, q query.Query) (links []string) {
+	request := bleve.NewSearchRequest(q)
+	request.SortBy([]string{"_id"})
+
+	result, err := index.Search(request)
+	require.NoError(t, err)
+
+	for _, hit := range result.Hits {
+		links = append(links, hit.ID)
+	}
+
+	return
+}
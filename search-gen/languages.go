@@ -0,0 +1,38 @@
+package gen
+
+import (
+	_ "github.com/blevesearch/bleve/analysis/lang/ar"
+	_ "github.com/blevesearch/bleve/analysis/lang/cjk"
+	_ "github.com/blevesearch/bleve/analysis/lang/ckb"
+	_ "github.com/blevesearch/bleve/analysis/lang/da"
+	_ "github.com/blevesearch/bleve/analysis/lang/de"
+	_ "github.com/blevesearch/bleve/analysis/lang/en"
+	_ "github.com/blevesearch/bleve/analysis/lang/es"
+	_ "github.com/blevesearch/bleve/analysis/lang/fa"
+	_ "github.com/blevesearch/bleve/analysis/lang/fi"
+	_ "github.com/blevesearch/bleve/analysis/lang/fr"
+	_ "github.com/blevesearch/bleve/analysis/lang/hi"
+	_ "github.com/blevesearch/bleve/analysis/lang/hu"
+	_ "github.com/blevesearch/bleve/analysis/lang/it"
+	_ "github.com/blevesearch/bleve/analysis/lang/nl"
+	_ "github.com/blevesearch/bleve/analysis/lang/no"
+	_ "github.com/blevesearch/bleve/analysis/lang/pt"
+	_ "github.com/blevesearch/bleve/analysis/lang/ro"
+	_ "github.com/blevesearch/bleve/analysis/lang/ru"
+	_ "github.com/blevesearch/bleve/analysis/lang/sv"
+	_ "github.com/blevesearch/bleve/analysis/lang/tr"
+)
+
+// DefaultLanguage is the language used for the search analyzers
+// when no language is given.
+const DefaultLanguage = "en"
+
+// languages contains the languages that have a bleve analyzer with
+// stemming, the analyzer is registered with the language as name and
+// the package is github.com/blevesearch/bleve/analysis/lang/<language>.
+var languages = map[string]bool{
+	"ar": true, "cjk": true, "ckb": true, "da": true, "de": true,
+	"en": true, "es": true, "fa": true, "fi": true, "fr": true,
+	"hi": true, "hu": true, "it": true, "nl": true, "no": true,
+	"pt": true, "ro": true, "ru": true, "sv": true, "tr": true,
+}
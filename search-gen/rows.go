@@ -0,0 +1,235 @@
+package gen
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"encoding/json"
+	"io"
+	"sort"
+
+	"github.com/blevesearch/bleve"
+	"github.com/blevesearch/bleve/index/store"
+	"github.com/blevesearch/bleve/index/upsidedown"
+	"github.com/blevesearch/bleve/mapping"
+	"github.com/blevesearch/bleve/registry"
+	"github.com/pkg/errors"
+)
+
+// StoreName is the name of the read-only bleve KV store that serves the
+// rows of a prebuilt index from memory, the rows are passed in the store
+// config.
+const StoreName = "go-service-doc"
+
+const rowsConfigKey = "rows"
+
+// backIndexRowType is the first byte of the key of the back index rows.
+const backIndexRowType = 'b'
+
+func init() {
+	if registry.KVStoreConstructorByName(StoreName) == nil {
+		registry.RegisterKVStore(StoreName, newStore)
+	}
+}
+
+// Open opens a prebuilt search index, i.e. the search index embedded in
+// the generated go-handler.
+func Open(searchIndex Index) (_ bleve.Index, err error) {
+	var indexMapping *mapping.IndexMappingImpl
+
+	if err = json.Unmarshal(searchIndex.Mapping, &indexMapping); err != nil {
+		err = errors.Wrap(err, "json.Unmarshal failed")
+		return
+	}
+
+	config := map[string]interface{}{rowsConfigKey: searchIndex.Rows}
+
+	index, err := bleve.NewUsing("", indexMapping, upsidedown.Name, StoreName, config)
+	if err != nil {
+		err = errors.Wrap(err, "bleve.NewUsing failed")
+		return
+	}
+
+	return index, nil
+}
+
+func newStore(mo store.MergeOperator, config map[string]interface{}) (_ store.KVStore, err error) {
+	rows, ok := config[rowsConfigKey].([]byte)
+	if !ok {
+		err = errors.New("the rows of the search index are missing")
+		return
+	}
+
+	kvstore := &readOnlyStore{mo: mo}
+
+	err = readRows(rows, func(key, value []byte) {
+		kvstore.rows = append(kvstore.rows, row{key: key, value: value})
+	})
+	if err != nil {
+		return
+	}
+
+	sorted := sort.SliceIsSorted(kvstore.rows, func(i, j int) bool {
+		return bytes.Compare(kvstore.rows[i].key, kvstore.rows[j].key) < 0
+	})
+	if !sorted {
+		err = errors.New("the rows of the search index aren't sorted")
+		return
+	}
+
+	return kvstore, nil
+}
+
+// dumpRows returns the rows of the KV store of the index, sorted by key,
+// gzipped and with the length of each key and value as a uvarint before
+// it.
+func dumpRows(searchIndex bleve.Index) (_ []byte, err error) {
+	_, kvstore, err := searchIndex.Advanced()
+	if err != nil {
+		err = errors.Wrap(err, "searchIndex.Advanced failed")
+		return
+	}
+
+	reader, err := kvstore.Reader()
+	if err != nil {
+		err = errors.Wrap(err, "kvstore.Reader failed")
+		return
+	}
+
+	// nolint: errcheck
+	defer reader.Close()
+
+	iterator := reader.RangeIterator(nil, nil)
+
+	// nolint: errcheck
+	defer iterator.Close()
+
+	buffer := &bytes.Buffer{}
+
+	writer, err := gzip.NewWriterLevel(buffer, gzip.BestCompression)
+	if err != nil {
+		err = errors.Wrap(err, "gzip.NewWriterLevel failed")
+		return
+	}
+
+	length := make([]byte, binary.MaxVarintLen64)
+
+	for ; iterator.Valid(); iterator.Next() {
+		value := iterator.Value()
+
+		if iterator.Key()[0] == backIndexRowType {
+			if value, err = sortBackIndexRow(value); err != nil {
+				return
+			}
+		}
+
+		for _, bs := range [][]byte{iterator.Key(), value} {
+			n := binary.PutUvarint(length, uint64(len(bs)))
+
+			if _, err = writer.Write(length[:n]); err != nil {
+				err = errors.Wrap(err, "gzip.Write failed")
+				return
+			}
+
+			if _, err = writer.Write(bs); err != nil {
+				err = errors.Wrap(err, "gzip.Write failed")
+				return
+			}
+		}
+	}
+
+	if err = writer.Close(); err != nil {
+		err = errors.Wrap(err, "gzip.Close failed")
+		return
+	}
+
+	return buffer.Bytes(), nil
+}
+
+// sortBackIndexRow sorts the entries of a back index row, which bleve
+// writes in the order of a map. The order doesn't matter to the index,
+// but without sorting the same pages would give different rows. The row
+// is decoded with the protobuf message of bleve, so a change of the
+// format fails instead of giving a corrupt index.
+func sortBackIndexRow(value []byte) (_ []byte, err error) {
+	var row upsidedown.BackIndexRowValue
+	if err = row.Unmarshal(value); err != nil {
+		return nil, errors.Wrap(err, "invalid back index row")
+	}
+
+	for _, entry := range row.TermsEntries {
+		sort.Strings(entry.Terms)
+	}
+
+	sort.Slice(row.TermsEntries, func(i, j int) bool {
+		return row.TermsEntries[i].GetField() < row.TermsEntries[j].GetField()
+	})
+
+	sort.SliceStable(row.StoredEntries, func(i, j int) bool {
+		a, b := row.StoredEntries[i], row.StoredEntries[j]
+		if a.GetField() != b.GetField() {
+			return a.GetField() < b.GetField()
+		}
+
+		return lessUints(a.ArrayPositions, b.ArrayPositions)
+	})
+
+	if value, err = row.Marshal(); err != nil {
+		return nil, errors.Wrap(err, "row.Marshal failed")
+	}
+
+	return value, nil
+}
+
+func lessUints(a, b []uint64) bool {
+	for idx := 0; idx < len(a) && idx < len(b); idx++ {
+		if a[idx] != b[idx] {
+			return a[idx] < b[idx]
+		}
+	}
+
+	return len(a) < len(b)
+}
+
+// readRows reads the rows dumped by dumpRows and calls set for each row.
+func readRows(rows []byte, set func(key, value []byte)) (err error) {
+	gzipReader, err := gzip.NewReader(bytes.NewReader(rows))
+	if err != nil {
+		return errors.Wrap(err, "gzip.NewReader failed")
+	}
+
+	reader := bufio.NewReader(gzipReader)
+
+	for {
+		var key, value []byte
+
+		if key, err = readRowBytes(reader); err == io.EOF {
+			return nil
+		}
+
+		if err == nil {
+			value, err = readRowBytes(reader)
+		}
+
+		if err != nil {
+			return errors.Wrap(err, "invalid search index rows")
+		}
+
+		set(key, value)
+	}
+}
+
+func readRowBytes(reader *bufio.Reader) (_ []byte, err error) {
+	length, err := binary.ReadUvarint(reader)
+	if err != nil {
+		return
+	}
+
+	bs := make([]byte, length)
+	if _, err = io.ReadFull(reader, bs); err != nil {
+		return
+	}
+
+	return bs, nil
+}
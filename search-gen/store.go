@@ -0,0 +1,163 @@
+package gen
+
+import (
+	"bytes"
+	"sort"
+
+	"github.com/blevesearch/bleve/index/store"
+	"github.com/pkg/errors"
+)
+
+type row struct {
+	key   []byte
+	value []byte
+}
+
+// readOnlyStore is a KV store of rows sorted by key, which serves a
+// prebuilt index from memory and can't be changed.
+type readOnlyStore struct {
+	mo   store.MergeOperator
+	rows []row
+}
+
+func (s *readOnlyStore) Reader() (store.KVReader, error) {
+	return &readOnlyReader{rows: s.rows}, nil
+}
+
+// Writer returns a writer that only accepts batches that don't change
+// the rows, since bleve stores the mapping of the index, which the rows
+// already contain, when the index is opened.
+func (s *readOnlyStore) Writer() (store.KVWriter, error) {
+	return &readOnlyWriter{store: s}, nil
+}
+
+func (s *readOnlyStore) Close() error {
+	return nil
+}
+
+type readOnlyReader struct {
+	rows []row
+}
+
+// search returns the index of the first row with a key that isn't less
+// than key.
+func search(rows []row, key []byte) int {
+	return sort.Search(len(rows), func(idx int) bool {
+		return bytes.Compare(rows[idx].key, key) >= 0
+	})
+}
+
+func (r *readOnlyReader) Get(key []byte) ([]byte, error) {
+	idx := search(r.rows, key)
+	if idx < len(r.rows) && bytes.Equal(r.rows[idx].key, key) {
+		return append([]byte{}, r.rows[idx].value...), nil
+	}
+
+	return nil, nil
+}
+
+func (r *readOnlyReader) MultiGet(keys [][]byte) ([][]byte, error) {
+	return store.MultiGet(r, keys)
+}
+
+func (r *readOnlyReader) PrefixIterator(prefix []byte) store.KVIterator {
+	rows := r.rows[search(r.rows, prefix):]
+
+	end := sort.Search(len(rows), func(idx int) bool {
+		return !bytes.HasPrefix(rows[idx].key, prefix)
+	})
+
+	return &readOnlyIterator{rows: rows[:end]}
+}
+
+func (r *readOnlyReader) RangeIterator(start, end []byte) store.KVIterator {
+	rows := r.rows[search(r.rows, start):]
+
+	if end != nil {
+		rows = rows[:search(rows, end)]
+	}
+
+	return &readOnlyIterator{rows: rows}
+}
+
+func (r *readOnlyReader) Close() error {
+	return nil
+}
+
+type readOnlyIterator struct {
+	rows []row
+	idx  int
+}
+
+func (i *readOnlyIterator) Seek(key []byte) {
+	i.idx = search(i.rows, key)
+}
+
+func (i *readOnlyIterator) Next() {
+	i.idx++
+}
+
+func (i *readOnlyIterator) Key() []byte {
+	key, _, _ := i.Current()
+	return key
+}
+
+func (i *readOnlyIterator) Value() []byte {
+	_, value, _ := i.Current()
+	return value
+}
+
+func (i *readOnlyIterator) Valid() bool {
+	return i.idx < len(i.rows)
+}
+
+func (i *readOnlyIterator) Current() ([]byte, []byte, bool) {
+	if !i.Valid() {
+		return nil, nil, false
+	}
+
+	return i.rows[i.idx].key, i.rows[i.idx].value, true
+}
+
+func (i *readOnlyIterator) Close() error {
+	return nil
+}
+
+type readOnlyWriter struct {
+	store *readOnlyStore
+}
+
+func (w *readOnlyWriter) NewBatch() store.KVBatch {
+	return store.NewEmulatedBatch(w.store.mo)
+}
+
+func (w *readOnlyWriter) NewBatchEx(options store.KVBatchOptions) ([]byte, store.KVBatch, error) {
+	return make([]byte, options.TotalBytes), w.NewBatch(), nil
+}
+
+func (w *readOnlyWriter) ExecuteBatch(batch store.KVBatch) error {
+	emulatedBatch, ok := batch.(*store.EmulatedBatch)
+	if !ok {
+		return errors.New("unsupported batch type")
+	}
+
+	reader := readOnlyReader{rows: w.store.rows}
+
+	for _, op := range emulatedBatch.Ops {
+		// A delete has a nil value.
+		value, _ := reader.Get(op.K)
+		if op.V == nil || value == nil || !bytes.Equal(op.V, value) {
+			return errors.Errorf("the search index is read-only, the row [%q] can't be changed", op.K)
+		}
+	}
+
+	if len(emulatedBatch.Merger.Merges) > 0 {
+		return errors.New("the search index is read-only, rows can't be merged")
+	}
+
+	return nil
+}
+
+func (w *readOnlyWriter) Close() error {
+	return nil
+}
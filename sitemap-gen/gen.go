@@ -0,0 +1,75 @@
+package gen
+
+import (
+	"encoding/xml"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/lonnblad/go-service-doc/core"
+)
+
+const (
+	// SitemapFilename is the name of the sitemap, which is served at the
+	// base path.
+	SitemapFilename    = "sitemap.xml"
+	SitemapContentType = "application/xml"
+
+	// RobotsFilename is the name of the robots.txt, which crawlers only
+	// look for at the root of the site.
+	RobotsFilename    = "robots.txt"
+	RobotsContentType = "text/plain; charset=utf-8"
+)
+
+type urlSet struct {
+	XMLName xml.Name `xml:"http://www.sitemaps.org/schemas/sitemap/0.9 urlset"`
+	URLs    []url    `xml:"url"`
+}
+
+type url struct {
+	Loc     string `xml:"loc"`
+	LastMod string `xml:"lastmod,omitempty"`
+}
+
+// BuildSitemap generates a sitemap with the absolute URLs of the pages,
+// baseURL is the URL of the site without the base path, and the times
+// the pages were last modified.
+func BuildSitemap(baseURL string, pages core.Pages) (_ []byte, err error) {
+	set := urlSet{}
+
+	for _, page := range pages {
+		u := url{Loc: strings.TrimSuffix(baseURL, "/") + page.WebPath}
+
+		if !page.LastModified.IsZero() {
+			u.LastMod = page.LastModified.UTC().Format(time.RFC3339)
+		}
+
+		set.URLs = append(set.URLs, u)
+	}
+
+	bs, err := xml.MarshalIndent(set, "", "  ")
+	if err != nil {
+		err = errors.Wrap(err, "xml.MarshalIndent failed")
+		return
+	}
+
+	return append([]byte(xml.Header), append(bs, '\n')...), nil
+}
+
+// BuildRobots generates a robots.txt that allows all pages except for
+// the disallowed paths, i.e. the search, and points to the sitemap.
+func BuildRobots(sitemapURL string, disallow []string) []byte {
+	var robots strings.Builder
+
+	robots.WriteString("User-agent: *\n")
+
+	for _, path := range disallow {
+		fmt.Fprintf(&robots, "Disallow: %s\n", path)
+	}
+
+	fmt.Fprintf(&robots, "\nSitemap: %s\n", sitemapURL)
+
+	return []byte(robots.String())
+}
@@ -0,0 +1,46 @@
+package gen_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lonnblad/go-service-doc/core"
+	sitemap_gen "github.com/lonnblad/go-service-doc/sitemap-gen"
+)
+
+func Test_BuildSitemap(t *testing.T) {
+	lastModified := time.Date(2021, 3, 1, 12, 30, 0, 0, time.FixedZone("CET", 3600))
+
+	pages := core.Pages{
+		{WebPath: "/docs", LastModified: lastModified},
+		{WebPath: "/docs/donkey-bar"},
+	}
+
+	sitemap, err := sitemap_gen.BuildSitemap("https://bars.example.com/", pages)
+	require.NoError(t, err)
+
+	expected := `<?xml version="1.0" encoding="UTF-8"?>
+<urlset xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">
+  <url>
+    <loc>https://bars.example.com/docs</loc>
+    <lastmod>2021-03-01T11:30:00Z</lastmod>
+  </url>
+  <url>
+    <loc>https://bars.example.com/docs/donkey-bar</loc>
+  </url>
+</urlset>
+`
+
+	assert.Equal(t, expected, string(sitemap))
+}
+
+func Test_BuildRobots(t *testing.T) {
+	robots := sitemap_gen.BuildRobots("https://bars.example.com/docs/sitemap.xml", []string{"/docs/search"})
+
+	expected := "User-agent: *\nDisallow: /docs/search\n\nSitemap: https://bars.example.com/docs/sitemap.xml\n"
+
+	assert.Equal(t, expected, string(robots))
+}
@@ -1,12 +1,19 @@
 package utils
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+	"path"
 	"regexp"
 	"strings"
 )
 
 const FilePermission = 0644
 
+var nonAlphanumericRegexp = regexp.MustCompile(`[^a-z0-9_]+`)
+
+const fingerprintLength = 6
+
 func ConvertToCamelCase(str string) string {
 	str = convertSpaceCaseToCamel(str)
 	str = convertKebabCaseToCamel(str)
@@ -62,3 +69,33 @@ func convertCamelCaseToKebab(str string) string {
 func convertSnakeCaseToKebab(str string) string {
 	return strings.ReplaceAll(str, "_", "-")
 }
+
+// Fingerprint returns a short hash of the content.
+func Fingerprint(content []byte) string {
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:])[:fingerprintLength]
+}
+
+// AddFingerprint adds the fingerprint before the file extension,
+// i.e. static/bars.svg will be static/bars.3f2a9c.svg.
+func AddFingerprint(filepath, fingerprint string) string {
+	if fingerprint == "" {
+		return filepath
+	}
+
+	ext := path.Ext(filepath)
+
+	return strings.TrimSuffix(filepath, ext) + "." + fingerprint + ext
+}
+
+// Slug returns a string that can be used as the ID of a heading,
+// i.e. get-bars-id for GET /bars/{id}.
+func Slug(str string) string {
+	return strings.Trim(nonAlphanumericRegexp.ReplaceAllString(strings.ToLower(str), "-"), "-")
+}
+
+// EscapeTableCell escapes text to be used in a cell of a Markdown table.
+func EscapeTableCell(str string) string {
+	str = strings.ReplaceAll(str, "|", "\\|")
+	return strings.Join(strings.Fields(str), " ")
+}
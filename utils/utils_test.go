@@ -21,6 +21,25 @@ var kebabCaseTCs = []tc{
 	{input: "aA", expected: "a-a"},
 }
 
+var fingerprintTCs = []struct {
+	name        string
+	input       string
+	fingerprint string
+	expected    string
+}{
+	{name: "with extension", input: "/static/bars.svg", fingerprint: "3f2a9c", expected: "/static/bars.3f2a9c.svg"},
+	{name: "without extension", input: "/static/bars", fingerprint: "3f2a9c", expected: "/static/bars.3f2a9c"},
+	{name: "dot in directory", input: "/v1.0/markdown.css", fingerprint: "8b1d2e", expected: "/v1.0/markdown.8b1d2e.css"},
+	{name: "without fingerprint", input: "/static/bars.svg", fingerprint: "", expected: "/static/bars.svg"},
+}
+
+var slugTCs = []tc{
+	{name: "words", input: "Bars API", expected: "bars-api"},
+	{name: "path", input: "GET /bars/{id}", expected: "get-bars-id"},
+	{name: "package", input: "bars.v1", expected: "bars-v1"},
+	{name: "snake case", input: "bar_id", expected: "bar_id"},
+}
+
 type tc struct {
 	name     string
 	input    string
@@ -48,3 +67,35 @@ func Test_ConvertToKebabCase(t *testing.T) {
 		})
 	}
 }
+
+func Test_AddFingerprint(t *testing.T) {
+	for _, tc := range fingerprintTCs {
+		testcase := tc
+
+		t.Run(testcase.name, func(t *testing.T) {
+			actual := utils.AddFingerprint(testcase.input, testcase.fingerprint)
+			assert.Equal(t, testcase.expected, actual)
+		})
+	}
+}
+
+func Test_Fingerprint(t *testing.T) {
+	assert.Len(t, utils.Fingerprint([]byte("content")), 6)
+	assert.Equal(t, utils.Fingerprint([]byte("content")), utils.Fingerprint([]byte("content")))
+	assert.NotEqual(t, utils.Fingerprint([]byte("content")), utils.Fingerprint([]byte("other content")))
+}
+
+func Test_Slug(t *testing.T) {
+	for _, tc := range slugTCs {
+		testcase := tc
+
+		t.Run(testcase.name, func(t *testing.T) {
+			actual := utils.Slug(testcase.input)
+			assert.Equal(t, testcase.expected, actual)
+		})
+	}
+}
+
+func Test_EscapeTableCell(t *testing.T) {
+	assert.Equal(t, `a \| b c`, utils.EscapeTableCell(" a | b\n c "))
+}